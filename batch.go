@@ -0,0 +1,118 @@
+package gorestpack
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Options controlling a batch capture
+type BatchOptions struct {
+	// Maximum number of captures running at the same time. Defaults to 4.
+	Concurrency int
+	// Time limit for each capture. Zero means no limit besides the batch context.
+	ItemTimeout time.Duration
+	// Called after each capture completes. Calls are serialized.
+	Progress func(progress BatchProgress)
+}
+
+// Progress of a running batch
+type BatchProgress struct {
+	// Index of the request that just completed.
+	Index int
+	// Error of the request that just completed, if any.
+	Err error
+	// Number of completed and failed requests so far.
+	Done   int
+	Failed int
+	// Number of requests in the batch.
+	Total int
+}
+
+// Summary of the failed requests of a batch. Individual errors are reported in the batch results.
+type BatchError struct {
+	Total  int
+	Failed int
+	// Errors of the failed requests, in request order.
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("gorestpack: %d of %d captures failed, first error: %v", e.Failed, e.Total, e.Errors[0])
+}
+
+func (e *BatchError) Unwrap() []error {
+	return e.Errors
+}
+
+// Runs capture for indexes 0 to total-1 with bounded concurrency and returns the error of each.
+// A failing capture does not stop the others; cancelling ctx fails the captures that have not started.
+func runBatch(ctx context.Context, total int, options BatchOptions, capture func(ctx context.Context, i int) error) ([]error, error) {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	errs := make([]error, total)
+	indexes := make(chan int)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	progress := BatchProgress{Total: total}
+
+	for w := 0; w < concurrency && w < total; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				err := ctx.Err()
+
+				if err == nil {
+					itemCtx, cancel := ctx, context.CancelFunc(func() {})
+					if options.ItemTimeout > 0 {
+						itemCtx, cancel = context.WithTimeout(ctx, options.ItemTimeout)
+					}
+
+					err = capture(itemCtx, i)
+					cancel()
+				}
+
+				mu.Lock()
+				errs[i] = err
+				progress.Index = i
+				progress.Err = err
+				progress.Done++
+				if err != nil {
+					progress.Failed++
+				}
+				if options.Progress != nil {
+					options.Progress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < total; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	batchErr := &BatchError{Total: total}
+	for _, err := range errs {
+		if err != nil {
+			batchErr.Failed++
+			batchErr.Errors = append(batchErr.Errors, err)
+		}
+	}
+
+	if batchErr.Failed > 0 {
+		return errs, batchErr
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,54 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Batch_PartialFailure(t *testing.T) {
+	server := newTestServer(t)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL))
+
+	requests := []ScreenshotRequest{
+		{URL: "https://google.com/"},
+		{URL: "https://google/"},
+		{HTML: "<h1>Test</h1>", Options: ScreenshotCaptureOptions{Format: "jpg"}},
+	}
+
+	var calls int32
+	results, err := client.CaptureBatch(context.Background(), requests, BatchOptions{
+		Concurrency: 2,
+		Progress: func(progress BatchProgress) {
+			atomic.AddInt32(&calls, 1)
+		},
+	})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed != 1 || batchErr.Total != 3 || !errors.Is(err, ErrRemoteNavigation) {
+		t.Errorf("Must summarize failures, get: %v", err)
+	}
+
+	if len(results) != 3 || results[0].Err != nil || results[0].Result.Image == "" || results[1].Err == nil || results[2].Request.HTML != "<h1>Test</h1>" || results[2].Err != nil {
+		t.Errorf("Must return ordered results, get: %+v", results)
+	}
+
+	if calls != 3 {
+		t.Errorf("Must report progress for each request, get: %d", calls)
+	}
+}
+
+func Test_Batch_ItemTimeout(t *testing.T) {
+	server := newTestServer(t)
+	server.SetLatency(time.Second)
+
+	client := NewHTMLToPDFClient(testToken, WithBaseURL(server.URL))
+
+	results, err := client.CaptureBatch(context.Background(), []HTMLToPDFRequest{{URL: "https://google.com/"}}, BatchOptions{ItemTimeout: 20 * time.Millisecond})
+
+	if err == nil || !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("Must time out item, get: %v", results[0].Err)
+	}
+}
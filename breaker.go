@@ -0,0 +1,278 @@
+package gorestpack
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Matched by the errors returned while a circuit is open
+var ErrCircuitOpen = errors.New("gorestpack: circuit breaker is open")
+
+// State of the circuit of an endpoint
+type CircuitState int
+
+const (
+	// Requests are sent normally
+	CircuitClosed CircuitState = iota
+	// Requests fail fast with a *CircuitOpenError
+	CircuitOpen
+	// A limited number of probe requests are sent to find out whether the endpoint recovered
+	CircuitHalfOpen
+)
+
+func (me CircuitState) String() string {
+	switch me {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Returned instead of sending a request while the circuit of its endpoint is open
+type CircuitOpenError struct {
+	Service  string
+	Endpoint string
+	// Time at which probe requests will be allowed again
+	Until time.Time
+}
+
+func (me *CircuitOpenError) Error() string {
+	return ErrCircuitOpen.Error() + " for " + me.Service + " " + me.Endpoint
+}
+
+func (me *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// Transition of the circuit of an endpoint
+type CircuitStateChange struct {
+	Service  string
+	Endpoint string
+	From     CircuitState
+	To       CircuitState
+}
+
+// Configuration of a CircuitBreaker
+type CircuitBreakerConfig struct {
+	// Ratio of failed requests within Window that opens the circuit. Defaults to 0.5.
+	FailureRatio float64
+	// Minimum number of requests within Window before the circuit may open. Defaults to 10.
+	MinRequests int
+	// Period over which requests are counted. Defaults to 30 seconds.
+	Window time.Duration
+	// Time the circuit stays open before probing the endpoint. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// Number of successful probe requests needed to close the circuit again. Defaults to 1.
+	Probes int
+	// Decide whether a request failed. Defaults to transport errors and status codes of 500 and above.
+	IsFailure func(resp *http.Response, err error) bool
+	// Called on every state change, e.g. to degrade gracefully while a circuit is open
+	OnStateChange func(change CircuitStateChange)
+}
+
+// Stops sending requests to an endpoint once too many of them fail, keeping a separate circuit per service endpoint.
+// A single CircuitBreaker may be shared by several clients.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	service     string
+	endpoint    string
+	state       CircuitState
+	generation  int
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+// Outcome of a request guarded by a circuit
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	// Requests abandoned by the caller do not count
+	circuitIgnored
+)
+
+// Create a new CircuitBreaker with supplied configuration
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = 0.5
+	}
+
+	if config.MinRequests < 1 {
+		config.MinRequests = 10
+	}
+
+	if config.Window <= 0 {
+		config.Window = 30 * time.Second
+	}
+
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+
+	if config.Probes < 1 {
+		config.Probes = 1
+	}
+
+	if config.IsFailure == nil {
+		config.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		}
+	}
+
+	return &CircuitBreaker{config: config, circuits: map[string]*circuit{}}
+}
+
+// Guard requests with breaker, failing fast with ErrCircuitOpen while an endpoint is unhealthy
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(cfg *config) {
+		cfg.breaker = breaker
+	}
+}
+
+// Current state of the circuit of an endpoint
+func (me *CircuitBreaker) State(service string, endpoint string) CircuitState {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	c, ok := me.circuits[service+" "+endpoint]
+
+	if !ok {
+		return CircuitClosed
+	}
+
+	if c.state == CircuitOpen && !time.Now().Before(c.openedAt.Add(me.config.OpenTimeout)) {
+		return CircuitHalfOpen
+	}
+
+	return c.state
+}
+
+// Checks whether a request to an endpoint may be sent. The returned function must be called with the outcome of the request.
+func (me *CircuitBreaker) allow(service string, endpoint string) (func(circuitOutcome), error) {
+	me.mu.Lock()
+
+	key := service + " " + endpoint
+	c, ok := me.circuits[key]
+
+	if !ok {
+		c = &circuit{service: service, endpoint: endpoint, windowStart: time.Now()}
+		me.circuits[key] = c
+	}
+
+	var changes []CircuitStateChange
+	now := time.Now()
+
+	if c.state == CircuitOpen {
+		until := c.openedAt.Add(me.config.OpenTimeout)
+
+		if now.Before(until) {
+			me.mu.Unlock()
+			return nil, &CircuitOpenError{Service: service, Endpoint: endpoint, Until: until}
+		}
+
+		changes = append(changes, me.transition(c, CircuitHalfOpen))
+	}
+
+	if c.state == CircuitHalfOpen {
+		if c.probes >= me.config.Probes {
+			me.mu.Unlock()
+			me.notify(changes)
+			return nil, &CircuitOpenError{Service: service, Endpoint: endpoint, Until: now}
+		}
+
+		c.probes++
+	} else if now.Sub(c.windowStart) > me.config.Window {
+		c.windowStart = now
+		c.requests = 0
+		c.failures = 0
+	}
+
+	generation := c.generation
+	me.mu.Unlock()
+	me.notify(changes)
+
+	var once sync.Once
+	return func(outcome circuitOutcome) {
+		once.Do(func() { me.done(key, generation, outcome) })
+	}, nil
+}
+
+func (me *CircuitBreaker) done(key string, generation int, outcome circuitOutcome) {
+	me.mu.Lock()
+
+	c := me.circuits[key]
+
+	// Outcomes of requests sent before the last state change are stale
+	if c.generation != generation || outcome == circuitIgnored {
+		if c.generation == generation && c.state == CircuitHalfOpen {
+			c.probes--
+		}
+
+		me.mu.Unlock()
+		return
+	}
+
+	var changes []CircuitStateChange
+
+	switch c.state {
+	case CircuitClosed:
+		c.requests++
+
+		if outcome == circuitFailure {
+			c.failures++
+		}
+
+		if c.requests >= me.config.MinRequests && float64(c.failures)/float64(c.requests) >= me.config.FailureRatio {
+			changes = append(changes, me.transition(c, CircuitOpen))
+		}
+	case CircuitHalfOpen:
+		if outcome == circuitFailure {
+			changes = append(changes, me.transition(c, CircuitOpen))
+		} else if c.successes++; c.successes >= me.config.Probes {
+			changes = append(changes, me.transition(c, CircuitClosed))
+		}
+	}
+
+	me.mu.Unlock()
+	me.notify(changes)
+}
+
+// Moves c to state, resetting its counters. Must be called with the lock held.
+func (me *CircuitBreaker) transition(c *circuit, state CircuitState) CircuitStateChange {
+	change := CircuitStateChange{Service: c.service, Endpoint: c.endpoint, From: c.state, To: state}
+
+	*c = circuit{service: c.service, endpoint: c.endpoint, state: state, generation: c.generation + 1, windowStart: time.Now()}
+
+	if state == CircuitOpen {
+		c.openedAt = time.Now()
+	}
+
+	return change
+}
+
+func (me *CircuitBreaker) notify(changes []CircuitStateChange) {
+	if me.config.OnStateChange == nil {
+		return
+	}
+
+	for _, change := range changes {
+		me.config.OnStateChange(change)
+	}
+}
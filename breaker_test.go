@@ -0,0 +1,103 @@
+package gorestpack
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/restpackio/gorestpack/gorestpacktest"
+)
+
+func Test_CircuitBreaker_Open(t *testing.T) {
+	server := newTestServer(t)
+	server.FailNext(gorestpacktest.FailServer, gorestpacktest.FailUnavailable)
+
+	var changes []CircuitStateChange
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		MinRequests: 2,
+		OpenTimeout: 50 * time.Millisecond,
+		OnStateChange: func(change CircuitStateChange) {
+			changes = append(changes, change)
+		},
+	})
+
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithCircuitBreaker(breaker))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Capture("https://google.com/"); !errors.Is(err, ErrServer) {
+			t.Fatalf("Must return server error, get: %v", err)
+		}
+	}
+
+	_, err := client.Capture("https://google.com/")
+
+	var circuitErr *CircuitOpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &circuitErr) || circuitErr.Endpoint != "/capture" || circuitErr.Until.IsZero() {
+		t.Errorf("Must fail fast while open, get: %v", err)
+	}
+
+	if len(server.Requests()) != 2 {
+		t.Errorf("Must not send requests while open, get: %d", len(server.Requests()))
+	}
+
+	if breaker.State(ServiceScreenshot, "/capture") != CircuitOpen || breaker.State(ServiceHTMLToPDF, "/convert") != CircuitClosed {
+		t.Errorf("Must keep a circuit per endpoint")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := client.Capture("https://google.com/"); err != nil {
+		t.Errorf("Must send probe once open timeout elapses, get: %v", err)
+	}
+
+	if breaker.State(ServiceScreenshot, "/capture") != CircuitClosed {
+		t.Errorf("Must close after successful probe")
+	}
+
+	expected := []CircuitStateChange{
+		{Service: ServiceScreenshot, Endpoint: "/capture", From: CircuitClosed, To: CircuitOpen},
+		{Service: ServiceScreenshot, Endpoint: "/capture", From: CircuitOpen, To: CircuitHalfOpen},
+		{Service: ServiceScreenshot, Endpoint: "/capture", From: CircuitHalfOpen, To: CircuitClosed},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Must report state changes, get: %+v", changes)
+	}
+
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Must report state change %d, get: %+v", i, changes[i])
+		}
+	}
+}
+
+func Test_CircuitBreaker_FailedProbe(t *testing.T) {
+	server := newTestServer(t)
+	server.FailNext(gorestpacktest.FailServer, gorestpacktest.FailServer)
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, OpenTimeout: 20 * time.Millisecond})
+	client := NewHTMLToPDFClient(testToken, WithBaseURL(server.URL), WithCircuitBreaker(breaker))
+
+	client.Capture("https://google.com/")
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := client.Capture("https://google.com/"); !errors.Is(err, ErrServer) {
+		t.Errorf("Must send probe, get: %v", err)
+	}
+
+	if _, err := client.Capture("https://google.com/"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Must open again after failed probe, get: %v", err)
+	}
+}
+
+func Test_CircuitBreaker_ClientErrors(t *testing.T) {
+	server := newTestServer(t)
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{MinRequests: 1})
+	client := NewScreenshotClient("INVALID_TOKEN", WithBaseURL(server.URL), WithCircuitBreaker(breaker))
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Capture("https://google.com/"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Must not open on client errors, get: %v", err)
+		}
+	}
+}
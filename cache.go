@@ -0,0 +1,364 @@
+package gorestpack
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Local store for capture results, keyed by a hash of the service, endpoint and capture options
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	// Store value for ttl. Entries stored with a ttl of zero or less do not expire.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// Hit and miss statistics of a cache
+type CacheStats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+	Bytes   int64
+}
+
+// Largest streamed output buffered for caching
+const maxStreamCacheBytes = 32 << 20
+
+// Serve identical captures from cache for ttl instead of calling the API. Both JSON results and binary outputs are cached.
+// A ttl of zero or less keeps entries until the cache evicts them to stay within its size limit.
+// Captures with the Privacy option set always bypass the cache.
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(cfg *config) {
+		cfg.cache = cache
+		cfg.cacheTTL = ttl
+	}
+}
+
+// Implemented by call options that may opt out of caching
+type cacheable interface {
+	cacheable() bool
+}
+
+// Stable cache key for an API request
+func cacheKey(service string, path string, payload []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(service))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Response body storing the output in cache once fully read
+type cachingBody struct {
+	io.ReadCloser
+	cache Cache
+	key   string
+	ttl   time.Duration
+	buf   bytes.Buffer
+	skip  bool
+}
+
+func (me *cachingBody) Read(p []byte) (int, error) {
+	n, err := me.ReadCloser.Read(p)
+
+	if !me.skip {
+		if me.buf.Len()+n > maxStreamCacheBytes {
+			me.skip = true
+			me.buf = bytes.Buffer{}
+		} else {
+			me.buf.Write(p[:n])
+		}
+	}
+
+	if err == io.EOF && !me.skip {
+		me.cache.Set(me.key, me.buf.Bytes(), me.ttl)
+		me.skip = true
+	}
+
+	return n, err
+}
+
+// Expiry time of an entry stored for ttl, zero if it does not expire
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(ttl)
+}
+
+func expired(expires time.Time) bool {
+	return !expires.IsZero() && time.Now().After(expires)
+}
+
+// In-memory least recently used cache bounded by the total size of its values
+type MemoryCache struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	stats   CacheStats
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Create a new MemoryCache holding at most maxBytes of values
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	return &MemoryCache{
+		maxBytes: maxBytes,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (me *MemoryCache) Get(key string) ([]byte, bool) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	element, ok := me.entries[key]
+
+	if ok && expired(element.Value.(*memoryEntry).expires) {
+		me.remove(element)
+		ok = false
+	}
+
+	if !ok {
+		me.stats.Misses++
+		return nil, false
+	}
+
+	me.stats.Hits++
+	me.order.MoveToFront(element)
+
+	return element.Value.(*memoryEntry).value, true
+}
+
+func (me *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	if int64(len(value)) > me.maxBytes {
+		return
+	}
+
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	if element, ok := me.entries[key]; ok {
+		me.remove(element)
+	}
+
+	entry := &memoryEntry{key: key, value: append([]byte(nil), value...), expires: expiry(ttl)}
+	me.entries[key] = me.order.PushFront(entry)
+	me.stats.Entries++
+	me.stats.Bytes += int64(len(value))
+
+	for me.stats.Bytes > me.maxBytes {
+		me.remove(me.order.Back())
+	}
+}
+
+// Current statistics of the cache
+func (me *MemoryCache) Stats() CacheStats {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.stats
+}
+
+func (me *MemoryCache) remove(element *list.Element) {
+	entry := me.order.Remove(element).(*memoryEntry)
+	delete(me.entries, entry.key)
+	me.stats.Entries--
+	me.stats.Bytes -= int64(len(entry.value))
+}
+
+// On-disk cache storing one file per entry in a directory, bounded by the total size of its files.
+// The least recently used entries are removed first when the limit is exceeded.
+// Entry files are named after the SHA-256 of their key; other files in the directory are left alone.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	sizes map[string]int64
+	used  map[string]time.Time
+	stats CacheStats
+}
+
+// Create a new DiskCache in dir holding at most maxBytes, picking up entries left by previous processes
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	cache := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		sizes:    map[string]int64{},
+		used:     map[string]time.Time{},
+	}
+
+	files, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		// Temporary files of writes interrupted by a previous process
+		if !file.IsDir() && diskCacheTemp.MatchString(file.Name()) {
+			os.Remove(filepath.Join(dir, file.Name()))
+			continue
+		}
+
+		if file.IsDir() || !diskCacheEntry.MatchString(file.Name()) {
+			continue
+		}
+
+		info, err := file.Info()
+
+		if err != nil {
+			continue
+		}
+
+		cache.sizes[file.Name()] = info.Size()
+		cache.used[file.Name()] = info.ModTime()
+		cache.stats.Entries++
+		cache.stats.Bytes += info.Size()
+	}
+
+	return cache, nil
+}
+
+func (me *DiskCache) Get(key string) ([]byte, bool) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	key = diskCacheName(key)
+	data, err := os.ReadFile(filepath.Join(me.dir, key))
+
+	// Entries start with their expiry time as unix nanoseconds, zero if they do not expire
+	if err != nil || len(data) < 8 || (binary.BigEndian.Uint64(data) != 0 && time.Now().UnixNano() > int64(binary.BigEndian.Uint64(data))) {
+		if err == nil {
+			me.remove(key)
+		}
+
+		me.stats.Misses++
+		return nil, false
+	}
+
+	me.stats.Hits++
+	me.used[key] = time.Now()
+
+	return data[8:], true
+}
+
+func (me *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	size := int64(len(value) + 8)
+
+	if size > me.maxBytes {
+		return
+	}
+
+	data := make([]byte, 8, size)
+	if expires := expiry(ttl); !expires.IsZero() {
+		binary.BigEndian.PutUint64(data, uint64(expires.UnixNano()))
+	}
+	data = append(data, value...)
+	key = diskCacheName(key)
+
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	tmp, err := os.CreateTemp(me.dir, ".tmp")
+
+	if err != nil {
+		return
+	}
+
+	if err := copyToFile(tmp, bytes.NewReader(data)); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	me.remove(key)
+
+	if err := os.Rename(tmp.Name(), filepath.Join(me.dir, key)); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	me.sizes[key] = size
+	me.used[key] = time.Now()
+	me.stats.Entries++
+	me.stats.Bytes += size
+
+	if me.stats.Bytes > me.maxBytes {
+		me.evict()
+	}
+}
+
+// Names of the files managed by a DiskCache
+var (
+	diskCacheEntry = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	diskCacheTemp  = regexp.MustCompile(`^\.tmp[0-9]+$`)
+)
+
+// File name of the entry stored under key
+func diskCacheName(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// Current statistics of the cache
+func (me *DiskCache) Stats() CacheStats {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.stats
+}
+
+func (me *DiskCache) evict() {
+	keys := make([]string, 0, len(me.used))
+	for key := range me.used {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return me.used[keys[i]].Before(me.used[keys[j]]) })
+
+	for _, key := range keys {
+		if me.stats.Bytes <= me.maxBytes {
+			return
+		}
+
+		me.remove(key)
+	}
+}
+
+func (me *DiskCache) remove(key string) {
+	size, ok := me.sizes[key]
+
+	if !ok {
+		return
+	}
+
+	os.Remove(filepath.Join(me.dir, key))
+	delete(me.sizes, key)
+	delete(me.used, key)
+	me.stats.Entries--
+	me.stats.Bytes -= size
+}
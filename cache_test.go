@@ -0,0 +1,223 @@
+package gorestpack
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/restpackio/gorestpack/gorestpacktest"
+)
+
+func Test_Cache_Capture(t *testing.T) {
+	server := newTestServer(t)
+	cache := NewMemoryCache(1 << 20)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithCache(cache, time.Minute))
+
+	first, err := client.Capture("https://google.com/")
+
+	if err != nil {
+		t.Fatalf("Must capture, get: %v", err)
+	}
+
+	second, err := client.Capture("https://google.com/")
+
+	if err != nil || second.Image != first.Image || second.Attempts != 0 {
+		t.Errorf("Must return cached result, get: %+v, %v", second, err)
+	}
+
+	if _, err := client.CaptureToReader("https://google.com/"); err != nil {
+		t.Fatalf("Must capture, get: %v", err)
+	}
+
+	if _, err := client.CaptureToReader("https://google.com/"); err != nil {
+		t.Fatalf("Must capture, get: %v", err)
+	}
+
+	if len(server.Requests()) != 2 {
+		t.Errorf("Must call API once per distinct capture, get: %d", len(server.Requests()))
+	}
+
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("Must count hits and misses, get: %+v", stats)
+	}
+}
+
+func Test_Cache_Privacy(t *testing.T) {
+	server := newTestServer(t)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithCache(NewMemoryCache(1<<20), time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Capture("https://google.com/", ScreenshotCaptureOptions{Privacy: true}); err != nil {
+			t.Fatalf("Must capture, get: %v", err)
+		}
+	}
+
+	if len(server.Requests()) != 2 {
+		t.Errorf("Must bypass cache for private captures, get: %d", len(server.Requests()))
+	}
+}
+
+func Test_Cache_Stream(t *testing.T) {
+	server := newTestServer(t)
+	client := NewHTMLToPDFClient(testToken, WithBaseURL(server.URL), WithCache(NewMemoryCache(1<<20), time.Minute))
+
+	var outputs [][]byte
+	for i := 0; i < 2; i++ {
+		stream, err := client.CaptureToStream("https://google.com/")
+
+		if err != nil {
+			t.Fatalf("Must capture, get: %v", err)
+		}
+
+		data, _ := io.ReadAll(stream)
+		stream.Close()
+		outputs = append(outputs, data)
+	}
+
+	if len(server.Requests()) != 1 || string(outputs[0]) != string(outputs[1]) {
+		t.Errorf("Must serve streamed output from cache, get: %d requests", len(server.Requests()))
+	}
+}
+
+func Test_MemoryCache_Limits(t *testing.T) {
+	cache := NewMemoryCache(10)
+
+	cache.Set("a", []byte("12345"), time.Minute)
+	cache.Set("b", []byte("12345"), time.Minute)
+	cache.Get("a")
+	cache.Set("c", []byte("12345"), time.Minute)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Must evict least recently used entry")
+	}
+
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("Must keep recently used entry")
+	}
+
+	cache.Set("d", []byte("1"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if _, ok := cache.Get("d"); ok {
+		t.Errorf("Must expire entries after ttl")
+	}
+
+	cache.Set("d", []byte("1"), 0)
+
+	if _, ok := cache.Get("d"); !ok {
+		t.Errorf("Must keep entries without ttl")
+	}
+
+	cache.Set("e", []byte("12345678901"), time.Minute)
+
+	if _, ok := cache.Get("e"); ok {
+		t.Errorf("Must not store entries larger than the limit")
+	}
+}
+
+func Test_DiskCache_Persist(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, 30)
+
+	if err != nil {
+		t.Fatalf("Must create cache, get: %v", err)
+	}
+
+	cache.Set("a", []byte("12345"), time.Minute)
+	cache.Set("b", []byte("12345"), time.Minute)
+
+	reopened, err := NewDiskCache(dir, 30)
+
+	if err != nil {
+		t.Fatalf("Must reopen cache, get: %v", err)
+	}
+
+	if data, ok := reopened.Get("a"); !ok || string(data) != "12345" {
+		t.Errorf("Must read entries left by previous cache, get: %q", data)
+	}
+
+	reopened.Set("c", []byte("12345"), time.Minute)
+
+	if stats := reopened.Stats(); stats.Entries != 2 || stats.Bytes > 30 || stats.Hits != 1 {
+		t.Errorf("Must evict entries over the size limit, get: %+v", stats)
+	}
+
+	if _, ok := reopened.Get("a"); !ok {
+		t.Errorf("Must keep recently used entry")
+	}
+}
+
+func Test_Cache_NoExpiry(t *testing.T) {
+	server := newTestServer(t)
+	disk, err := NewDiskCache(t.TempDir(), 1<<20)
+
+	if err != nil {
+		t.Fatalf("Must create cache, get: %v", err)
+	}
+
+	for _, cache := range []Cache{NewMemoryCache(1 << 20), disk} {
+		server.Reset()
+		client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithCache(cache, 0))
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.Capture("https://google.com/"); err != nil {
+				t.Fatalf("Must capture, get: %v", err)
+			}
+		}
+
+		if len(server.Requests()) != 1 {
+			t.Errorf("Must serve cached result without ttl, get: %d requests", len(server.Requests()))
+		}
+	}
+}
+
+func Test_Cache_Tenants(t *testing.T) {
+	server := gorestpacktest.NewServer("TOKEN_A", "TOKEN_B")
+	t.Cleanup(server.Close)
+
+	tokens := TenantToken(func(ctx context.Context, tenant string) (string, error) {
+		return "TOKEN_" + tenant, nil
+	})
+
+	client := NewScreenshotClient("", WithBaseURL(server.URL), WithTokenProvider(tokens), WithCache(NewMemoryCache(1<<20), time.Minute))
+
+	for _, tenant := range []string{"A", "B", "A"} {
+		if _, err := client.CaptureContext(ContextWithTenant(context.Background(), tenant), "https://google.com/"); err != nil {
+			t.Fatalf("Must capture, get: %v", err)
+		}
+	}
+
+	if n := len(server.Requests()); n != 2 {
+		t.Errorf("Must not share cached results between tenants, get: %d requests", n)
+	}
+}
+
+func Test_DiskCache_ForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "important.txt"), []byte("keep me"), 0644)
+	os.WriteFile(filepath.Join(dir, ".tmp123"), []byte("partial"), 0644)
+
+	cache, err := NewDiskCache(dir, 30)
+
+	if err != nil {
+		t.Fatalf("Must create cache, get: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".tmp123")); !os.IsNotExist(err) {
+		t.Errorf("Must remove leftover temporary files")
+	}
+
+	cache.Set("a", []byte("1234567890"), time.Minute)
+	cache.Set("b", []byte("1234567890"), time.Minute)
+
+	if data, err := os.ReadFile(filepath.Join(dir, "important.txt")); err != nil || string(data) != "keep me" {
+		t.Errorf("Must not evict files it does not own, get: %v", err)
+	}
+
+	if stats := cache.Stats(); stats.Entries != 1 {
+		t.Errorf("Must only count its own entries, get: %+v", stats)
+	}
+}
@@ -2,15 +2,612 @@
 package gorestpack
 
 import (
-	"github.com/eknkc/request"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Root client giving access to every Restpack service. The services share the HTTP transport,
+// limiter, retry policy, middleware and other options the Client was created with.
+type Client struct {
+	screenshot *screenshotClient
+	htmlToPDF  *htmlToPDFClient
+}
+
+// Create a new Client with supplied restpack.io access key and optional client options
+func New(accessToken string, options ...Option) *Client {
+	cfg := newConfig(options)
+	httpClient := cfg.newHTTPClient()
+
+	return &Client{
+		screenshot: &screenshotClient{client: newClient(accessToken, ServiceScreenshot, cfg, httpClient)},
+		htmlToPDF:  &htmlToPDFClient{client: newClient(accessToken, ServiceHTMLToPDF, cfg, httpClient)},
+	}
+}
+
+// Restpack Screenshot API Client
+func (me *Client) Screenshot() ScreenshotClient {
+	return me.screenshot
+}
+
+// Restpack HTML to PDF API Client
+func (me *Client) HTMLToPDF() HTMLToPDFClient {
+	return me.htmlToPDF
+}
+
 type client struct {
-	httpClient  request.Client
-	accessToken string
+	httpClient  *http.Client
+	tokens      TokenProvider
+	service     string
 	basePath    string
+	userAgent   string
+	retryPolicy RetryPolicy
+	limiter     Limiter
+	handler     Handler
+	callHooks   []CallHook
+	cache       Cache
+	cacheTTL    time.Duration
+	dedup       *Deduplicator
+	breaker     *CircuitBreaker
+	hedger      *hedger
+	validate    bool
+}
+
+func newClient(accessToken string, service string, cfg *config, httpClient *http.Client) *client {
+	tokens := cfg.tokenProvider
+
+	if tokens == nil {
+		tokens = StaticToken(accessToken)
+	}
+
+	me := &client{
+		httpClient:  httpClient,
+		tokens:      tokens,
+		service:     service,
+		basePath:    strings.TrimRight(cfg.baseURL, "/") + "/api/" + service + "/v5",
+		userAgent:   cfg.userAgent,
+		retryPolicy: cfg.retryPolicy,
+		limiter:     cfg.limiter,
+		cache:       cfg.cache,
+		cacheTTL:    cfg.cacheTTL,
+		dedup:       cfg.dedup,
+		breaker:     cfg.breaker,
+		callHooks:   cfg.callHooks,
+		validate:    !cfg.skipValidation,
+	}
+
+	if cfg.hedgePolicy != nil {
+		me.hedger = newHedger(*cfg.hedgePolicy)
+	}
+
+	middleware := cfg.middleware
+
+	if cfg.logger != nil {
+		middleware = append(middleware[:len(middleware):len(middleware)], logging(cfg.logger, cfg.logSuccess, cfg.logFailure))
+	}
+
+	me.handler = chain(me.roundTrip, middleware)
+
+	return me
+}
+
+// Completed API response with its fully read body
+type response struct {
+	*http.Response
+	data []byte
+	// Number of attempts made, including retries
+	attempts int
+}
+
+// Sends body as a JSON payload and returns the response along with the fully read response body.
+// If ctx is cancelled while the request is in flight, ctx.Err() is returned.
+// Responses with a status code above 300 are returned along with an *APIError.
+// Failed attempts are retried according to the client's RetryPolicy.
+// Responses found in the client's Cache are returned without calling the API and report zero attempts.
+func (me *client) do(ctx context.Context, method string, path string, body interface{}) (*response, error) {
+	payload, err := me.marshal(body)
+	ctx, finish := me.startCall(ctx, method, path, payload)
+
+	if err != nil {
+		finish(CallResult{Err: err})
+		return nil, err
+	}
+
+	res, err := me.respond(ctx, method, path, body, payload)
+
+	if err != nil {
+		finish(CallResult{Attempts: errorAttempts(err), Err: err})
+		return nil, err
+	}
+
+	finish(CallResult{Attempts: res.attempts})
+	return res, nil
+}
+
+// Sends the marshaled body of a call, answering from the Cache or sharing identical requests in flight where configured
+func (me *client) respond(ctx context.Context, method string, path string, body interface{}, payload []byte) (*response, error) {
+	key, err := me.requestKey(ctx, path, payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	storeKey, cached, ok := me.cached(key, body)
+
+	if ok {
+		return &response{Response: &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, data: cached}, nil
+	}
+
+	if me.dedup != nil {
+		return me.dedup.do(ctx, key, func(ctx context.Context) (*response, error) {
+			return me.fetch(ctx, method, path, payload, storeKey)
+		})
+	}
+
+	return me.fetch(ctx, method, path, payload, storeKey)
+}
+
+// Runs the client's CallHooks for a call, returning the context of the call and a function ending it
+func (me *client) startCall(ctx context.Context, method string, path string, payload []byte) (context.Context, func(CallResult)) {
+	if len(me.callHooks) == 0 {
+		return ctx, func(CallResult) {}
+	}
+
+	req := &Request{
+		Service:  me.service,
+		Method:   method,
+		Endpoint: path,
+		URL:      me.basePath + path,
+		Options:  payload,
+	}
+
+	finishes := make([]func(CallResult), len(me.callHooks))
+
+	for i, hook := range me.callHooks {
+		ctx, finishes[i] = hook(ctx, req)
+	}
+
+	return ctx, func(res CallResult) {
+		for i := len(finishes) - 1; i >= 0; i-- {
+			finishes[i](res)
+		}
+	}
+}
+
+// Number of attempts reported by the error of a call
+func errorAttempts(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Attempts
+	}
+
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.Attempts
+	}
+
+	return 0
+}
+
+// Key identifying a request in the Cache and the Deduplicator, empty if neither is used. It includes the token
+// resolved for ctx, so results are never shared between tenants. A TokenReporter such as TokenPool is shared by every
+// caller and picks a token per attempt, so its tokens are left out.
+func (me *client) requestKey(ctx context.Context, path string, payload []byte) (string, error) {
+	if me.cache == nil && me.dedup == nil {
+		return "", nil
+	}
+
+	if _, ok := me.tokens.(TokenReporter); ok {
+		return cacheKey(me.service, path, payload), nil
+	}
+
+	token, err := me.tokens.Token(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return cacheKey(me.service+" "+token, path, payload), nil
+}
+
+// Sends payload with retries and stores the response in the client's Cache under key, unless it is empty
+func (me *client) fetch(ctx context.Context, method string, path string, payload []byte, key string) (*response, error) {
+	var res *response
+	attempts, err := me.retry(ctx, func() (*http.Response, error) {
+		var err error
+		res, err = me.send(ctx, method, path, payload)
+
+		if res == nil {
+			return nil, err
+		}
+		return res.Response, err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.attempts = attempts
+
+	if key != "" {
+		me.cache.Set(key, res.data, me.cacheTTL)
+	}
+
+	return res, nil
+}
+
+// Same as do but hands back the live response body instead of reading it.
+// The returned reader must be closed by the caller.
+// With a Deduplicator the body is read in full and shared with identical requests in flight.
+func (me *client) stream(ctx context.Context, method string, path string, body interface{}) (io.ReadCloser, error) {
+	payload, err := me.marshal(body)
+	ctx, finish := me.startCall(ctx, method, path, payload)
+
+	if err != nil {
+		finish(CallResult{Err: err})
+		return nil, err
+	}
+
+	stream, attempts, err := me.openStream(ctx, method, path, body, payload)
+
+	if err != nil {
+		finish(CallResult{Attempts: errorAttempts(err), Err: err})
+		return nil, err
+	}
+
+	if len(me.callHooks) == 0 {
+		return stream, nil
+	}
+
+	return &callBody{ReadCloser: stream, finish: func(err error) { finish(CallResult{Attempts: attempts, Err: err}) }}, nil
+}
+
+// Opens the response body of a call along with the number of attempts made, zero when served from the Cache
+func (me *client) openStream(ctx context.Context, method string, path string, body interface{}, payload []byte) (io.ReadCloser, int, error) {
+	if me.dedup != nil {
+		res, err := me.respond(ctx, method, path, body, payload)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return io.NopCloser(bytes.NewReader(res.data)), res.attempts, nil
+	}
+
+	key, err := me.requestKey(ctx, path, payload)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, cached, ok := me.cached(key, body)
+
+	if ok {
+		return io.NopCloser(bytes.NewReader(cached)), 0, nil
+	}
+
+	var stream io.ReadCloser
+	attempts, err := me.retry(ctx, func() (*http.Response, error) {
+		resp, err := me.open(ctx, method, path, payload)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode > 300 {
+			defer resp.Body.Close()
+
+			data, _ := io.ReadAll(resp.Body)
+			return resp, newAPIError(resp, data, path)
+		}
+
+		stream = &streamBody{ctx: ctx, body: resp.Body}
+		return resp, nil
+	})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if key != "" {
+		stream = &cachingBody{ReadCloser: stream, cache: me.cache, key: key, ttl: me.cacheTTL}
+	}
+
+	return stream, attempts, nil
+}
+
+// Validates body, unless disabled, and encodes it as the JSON payload of a request
+func (me *client) marshal(body interface{}) ([]byte, error) {
+	if v, ok := body.(interface{ Validate() error }); ok && me.validate {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(body)
+}
+
+// Looks the request stored under key up in the client's Cache. Returns an empty key if the request must not be cached.
+func (me *client) cached(key string, body interface{}) (string, []byte, bool) {
+	if me.cache == nil {
+		return "", nil, false
+	}
+
+	if c, ok := body.(cacheable); ok && !c.cacheable() {
+		return "", nil, false
+	}
+
+	data, ok := me.cache.Get(key)
+
+	return key, data, ok
+}
+
+// Runs attempt until it succeeds, fails with a non retryable error or the client's RetryPolicy is exhausted.
+// An invalid token is refreshed and retried once if the TokenProvider supports it,
+// and a TokenReporter may retry the request with another token.
+// Returns the number of attempts made. Failures other than an *APIError or a context error are wrapped in a *RequestError.
+func (me *client) retry(ctx context.Context, attempt func() (*http.Response, error)) (int, error) {
+	n, err := me.retryLoop(ctx, attempt)
+
+	if report, ok := ctx.Value(attemptReporterKey{}).(func(int)); ok {
+		report(n)
+	}
+
+	var apiErr *APIError
+	if err != nil && !errors.As(err, &apiErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return n, &RequestError{Attempts: n, Err: err}
+	}
+
+	return n, err
+}
+
+// Attempt loop of retry, returning the number of attempts made and the error of the last one
+func (me *client) retryLoop(ctx context.Context, attempt func() (*http.Response, error)) (int, error) {
+	refreshed := false
+
+	for n := 1; ; n++ {
+		resp, err := attempt()
+
+		if apiErr, ok := err.(*APIError); ok {
+			apiErr.Attempts = n
+		}
+
+		if reporter, ok := me.tokens.(TokenReporter); ok && resp != nil && resp.Request != nil {
+			if reporter.Report(resp.Request.Header.Get("x-access-token"), err) {
+				continue
+			}
+		}
+
+		if refresher, ok := me.tokens.(TokenRefresher); ok && !refreshed && errors.Is(err, ErrInvalidToken) {
+			refreshed = true
+
+			if refresher.Refresh(ctx) == nil {
+				continue
+			}
+		}
+
+		if err == nil || n >= me.retryPolicy.MaxAttempts || !me.retryPolicy.retryable(err) {
+			return n, err
+		}
+
+		var header http.Header
+		if resp != nil {
+			header = resp.Header
+		}
+
+		if err := sleepContext(ctx, me.retryPolicy.delay(n, header)); err != nil {
+			return n, err
+		}
+	}
+}
+
+// Performs a single attempt of an API request and reads the whole response body
+func (me *client) send(ctx context.Context, method string, path string, payload []byte) (*response, error) {
+	resp, err := me.open(ctx, method, path, payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	res := &response{Response: resp, data: data}
+
+	if resp.StatusCode > 300 {
+		return res, newAPIError(resp, data, path)
+	}
+
+	return res, nil
+}
+
+// Performs a single attempt of an API request through the middleware chain and returns the response with its body unread.
+// Slow attempts are hedged according to the client's HedgePolicy.
+func (me *client) open(ctx context.Context, method string, path string, payload []byte) (*http.Response, error) {
+	token, err := me.tokens.Token(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := func(ctx context.Context) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		header.Set("x-access-token", token)
+
+		if me.userAgent != "" {
+			header.Set("User-Agent", me.userAgent)
+		}
+
+		return me.handler(ctx, &Request{
+			Service:  me.service,
+			Method:   method,
+			Endpoint: path,
+			URL:      me.basePath + path,
+			Options:  payload,
+			Header:   header,
+		})
+	}
+
+	var resp *http.Response
+
+	if me.hedger != nil {
+		resp, err = me.hedger.run(ctx, attempt)
+	} else {
+		resp, err = attempt(ctx)
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Innermost Handler sending the request over HTTP. Closing the response body releases the limiter.
+func (me *client) roundTrip(ctx context.Context, r *Request) (*http.Response, error) {
+	req, err := http.NewRequest(r.Method, r.URL, bytes.NewReader(r.Options))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header = r.Header
+
+	finish := func(circuitOutcome) {}
+
+	if me.breaker != nil {
+		finish, err = me.breaker.allow(r.Service, r.Endpoint)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	release := func() {}
+
+	if me.limiter != nil {
+		release, err = me.limiter.Acquire(ctx)
+
+		if err != nil {
+			finish(circuitIgnored)
+			return nil, err
+		}
+	}
+
+	resp, err := me.httpClient.Do(req)
+
+	if me.breaker != nil {
+		switch {
+		case err != nil && ctx.Err() != nil:
+			finish(circuitIgnored)
+		case me.breaker.config.IsFailure(resp, err):
+			finish(circuitFailure)
+		default:
+			finish(circuitSuccess)
+		}
+	}
+
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	if me.limiter != nil {
+		me.limiter.Observe(resp)
+	}
+
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+// Same as do but decodes the JSON response body into v.
+func (me *client) doStruct(ctx context.Context, method string, path string, body interface{}, v interface{}) (*response, error) {
+	resp, err := me.do(ctx, method, path, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(resp.data, v); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Live response body returned by stream
+type streamBody struct {
+	ctx  context.Context
+	body io.ReadCloser
+}
+
+func (me *streamBody) Read(p []byte) (int, error) {
+	n, err := me.body.Read(p)
+
+	if err != nil && err != io.EOF && me.ctx.Err() != nil {
+		return n, me.ctx.Err()
+	}
+
+	return n, err
+}
+
+func (me *streamBody) Close() error {
+	return me.body.Close()
+}
+
+// Response body of a streamed call, ending the call once closed with the first read error if any
+type callBody struct {
+	io.ReadCloser
+	finish func(err error)
+	err    error
+	once   sync.Once
+}
+
+func (me *callBody) Read(p []byte) (int, error) {
+	n, err := me.ReadCloser.Read(p)
+
+	if err != nil && err != io.EOF && me.err == nil {
+		me.err = err
+	}
+
+	return n, err
+}
+
+func (me *callBody) Close() error {
+	err := me.ReadCloser.Close()
+	me.once.Do(func() { me.finish(me.err) })
+	return err
+}
+
+// Response body releasing the limiter once closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
 }
 
-func (me *client) do(method string, path string) request.Session {
-	return me.httpClient.Do(method, me.basePath+path).Header("x-access-token", me.accessToken)
+func (me *releaseBody) Close() error {
+	err := me.ReadCloser.Close()
+	me.once.Do(me.release)
+	return err
 }
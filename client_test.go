@@ -0,0 +1,30 @@
+package gorestpack
+
+import (
+	"testing"
+)
+
+func Test_Client_Shared(t *testing.T) {
+	server := newTestServer(t)
+	limiter := NewRateLimiter(RateLimiterConfig{RequestsPerSecond: 100})
+
+	client := New(testToken, WithBaseURL(server.URL), WithLimiter(limiter), WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+
+	if client.screenshot.httpClient != client.htmlToPDF.httpClient || client.screenshot.limiter != client.htmlToPDF.limiter {
+		t.Errorf("Services must share transport and limiter")
+	}
+
+	if _, err := client.Screenshot().Capture("https://google.com/"); err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if _, err := client.HTMLToPDF().Capture("https://google.com/"); err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	requests := server.Requests()
+
+	if len(requests) != 2 || requests[0].Service != ServiceScreenshot || requests[1].Service != ServiceHTMLToPDF {
+		t.Errorf("Must call both services, get: %+v", requests)
+	}
+}
@@ -0,0 +1,109 @@
+package gorestpack
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Collapses concurrent identical API requests into a single upstream request.
+// A Deduplicator may be shared by several clients.
+type Deduplicator struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+
+	calls     int64
+	collapsed int64
+}
+
+// Request and collapse counts of a Deduplicator
+type DeduplicatorStats struct {
+	// Number of requests made through the Deduplicator
+	Calls int64
+	// Number of requests served by another identical request in flight
+	Collapsed int64
+}
+
+// Upstream request shared by identical callers
+type flight struct {
+	done    chan struct{}
+	res     *response
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Create a new Deduplicator
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{flights: map[string]*flight{}}
+}
+
+// Collapse identical captures running at the same time into one API request whose result is handed to every caller.
+// Streamed outputs are buffered in memory while deduplication is enabled.
+func WithDeduplicator(dedup *Deduplicator) Option {
+	return func(cfg *config) {
+		cfg.dedup = dedup
+	}
+}
+
+// Current statistics of the Deduplicator
+func (me *Deduplicator) Stats() DeduplicatorStats {
+	return DeduplicatorStats{
+		Calls:     atomic.LoadInt64(&me.calls),
+		Collapsed: atomic.LoadInt64(&me.collapsed),
+	}
+}
+
+// Runs fn once for all concurrent callers with the same key. The shared request keeps the values of the first caller's ctx
+// and is cancelled once every caller waiting for it has given up.
+func (me *Deduplicator) do(ctx context.Context, key string, fn func(ctx context.Context) (*response, error)) (*response, error) {
+	atomic.AddInt64(&me.calls, 1)
+
+	me.mu.Lock()
+	f, ok := me.flights[key]
+
+	if ok {
+		atomic.AddInt64(&me.collapsed, 1)
+	} else {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		me.flights[key] = f
+
+		go func() {
+			f.res, f.err = fn(flightCtx)
+			cancel()
+
+			me.mu.Lock()
+			if me.flights[key] == f {
+				delete(me.flights, key)
+			}
+			me.mu.Unlock()
+
+			close(f.done)
+		}()
+	}
+
+	f.waiters++
+	me.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		me.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 && me.flights[key] == f {
+			delete(me.flights, key)
+			f.cancel()
+		}
+		me.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	// Every caller gets its own copy of the output
+	return &response{Response: f.res.Response, data: append([]byte(nil), f.res.data...), attempts: f.res.attempts}, nil
+}
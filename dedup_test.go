@@ -0,0 +1,123 @@
+package gorestpack
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/restpackio/gorestpack/gorestpacktest"
+)
+
+func Test_Deduplicator_Collapse(t *testing.T) {
+	server := newTestServer(t)
+	server.SetLatency(50 * time.Millisecond)
+
+	dedup := NewDeduplicator()
+	client := NewHTMLToPDFClient(testToken, WithBaseURL(server.URL), WithDeduplicator(dedup))
+
+	outputs := make([][]byte, 5)
+	errs := make([]error, 5)
+
+	var wg sync.WaitGroup
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			stream, err := client.CaptureHTMLToStream("<h1>Invoice</h1>")
+
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			defer stream.Close()
+			outputs[i], errs[i] = io.ReadAll(stream)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range outputs {
+		if errs[i] != nil || len(outputs[i]) == 0 || string(outputs[i]) != string(outputs[0]) {
+			t.Errorf("Must share output with every caller, get: %v", errs[i])
+		}
+	}
+
+	if len(server.Requests()) != 1 {
+		t.Errorf("Must make a single API request, get: %d", len(server.Requests()))
+	}
+
+	if stats := dedup.Stats(); stats.Calls != 5 || stats.Collapsed != 4 {
+		t.Errorf("Must count collapsed calls, get: %+v", stats)
+	}
+}
+
+func Test_Deduplicator_CancelWaiter(t *testing.T) {
+	server := newTestServer(t)
+	server.SetLatency(100 * time.Millisecond)
+
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithDeduplicator(NewDeduplicator()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.CaptureContext(ctx, "https://google.com/")
+		done <- err
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	res, err := client.Capture("https://google.com/")
+
+	if err != nil || res.Image == "" {
+		t.Errorf("Must complete for remaining callers, get: %v", err)
+	}
+
+	if err := <-done; err != context.DeadlineExceeded {
+		t.Errorf("Must return context error for cancelled caller, get: %v", err)
+	}
+}
+
+func Test_Deduplicator_Tenants(t *testing.T) {
+	server := gorestpacktest.NewServer("TOKEN_A", "TOKEN_B")
+	t.Cleanup(server.Close)
+	server.SetLatency(50 * time.Millisecond)
+
+	tokens := TenantToken(func(ctx context.Context, tenant string) (string, error) {
+		return "TOKEN_" + tenant, nil
+	})
+
+	dedup := NewDeduplicator()
+	client := NewScreenshotClient("", WithBaseURL(server.URL), WithTokenProvider(tokens), WithDeduplicator(dedup))
+
+	var wg sync.WaitGroup
+	for _, tenant := range []string{"A", "B", "A"} {
+		wg.Add(1)
+		go func(tenant string) {
+			defer wg.Done()
+
+			if _, err := client.CaptureContext(ContextWithTenant(context.Background(), tenant), "https://google.com/"); err != nil {
+				t.Errorf("Must capture, get: %v", err)
+			}
+		}(tenant)
+	}
+	wg.Wait()
+
+	var sent []string
+	for _, req := range server.Requests() {
+		sent = append(sent, req.Token)
+	}
+	sort.Strings(sent)
+
+	if len(sent) != 2 || sent[0] != "TOKEN_A" || sent[1] != "TOKEN_B" {
+		t.Errorf("Must only collapse requests of the same tenant, get: %v", sent)
+	}
+
+	if stats := dedup.Stats(); stats.Collapsed != 1 {
+		t.Errorf("Must collapse requests of the same tenant, get: %+v", stats)
+	}
+}
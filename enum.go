@@ -0,0 +1,181 @@
+package gorestpack
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Capturing mode of the Screenshot API
+type Mode string
+
+const (
+	ModeFullPage Mode = "fullpage"
+	ModeViewport Mode = "viewport"
+	ModeElement  Mode = "element"
+)
+
+// Output format of the Screenshot API
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatJPEG Format = "jpg"
+	FormatPDF  Format = "pdf"
+	FormatHTML Format = "html"
+)
+
+// Event to wait for before capturing a page
+type Wait string
+
+const (
+	WaitLoad    Wait = "load"
+	WaitNetwork Wait = "network"
+)
+
+// CSS media type to emulate
+type Media string
+
+const (
+	MediaScreen Media = "screen"
+	MediaPrint  Media = "print"
+)
+
+// PDF page orientation
+type Orientation string
+
+const (
+	OrientationPortrait  Orientation = "portrait"
+	OrientationLandscape Orientation = "landscape"
+)
+
+// PDF page size
+type Page string
+
+const (
+	PageA0      Page = "A0"
+	PageA1      Page = "A1"
+	PageA2      Page = "A2"
+	PageA3      Page = "A3"
+	PageA4      Page = "A4"
+	PageA5      Page = "A5"
+	PageA6      Page = "A6"
+	PageLetter  Page = "Letter"
+	PageLegal   Page = "Legal"
+	PageTabloid Page = "Tabloid"
+	PageLedger  Page = "Ledger"
+	// Single page fitting the whole document
+	PageFull Page = "Full"
+)
+
+var (
+	modes        = []string{string(ModeFullPage), string(ModeViewport), string(ModeElement)}
+	formats      = []string{string(FormatPNG), string(FormatJPEG), string(FormatPDF), string(FormatHTML)}
+	waits        = []string{string(WaitLoad), string(WaitNetwork)}
+	media        = []string{string(MediaScreen), string(MediaPrint)}
+	orientations = []string{string(OrientationPortrait), string(OrientationLandscape)}
+	pages        = []string{
+		string(PageA0), string(PageA1), string(PageA2), string(PageA3), string(PageA4), string(PageA5), string(PageA6),
+		string(PageLetter), string(PageLegal), string(PageTabloid), string(PageLedger), string(PageFull),
+	}
+)
+
+func (me Mode) MarshalJSON() ([]byte, error) {
+	return marshalEnum("Mode", string(me), modes)
+}
+
+func (me *Mode) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("Mode", data, (*string)(me), modes)
+}
+
+func (me Format) MarshalJSON() ([]byte, error) {
+	return marshalEnum("Format", string(me), formats)
+}
+
+func (me *Format) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("Format", data, (*string)(me), formats)
+}
+
+func (me Wait) MarshalJSON() ([]byte, error) {
+	return marshalEnum("Wait", string(me), waits)
+}
+
+func (me *Wait) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("Wait", data, (*string)(me), waits)
+}
+
+func (me Media) MarshalJSON() ([]byte, error) {
+	return marshalEnum("Media", string(me), media)
+}
+
+func (me *Media) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("Media", data, (*string)(me), media)
+}
+
+func (me Orientation) MarshalJSON() ([]byte, error) {
+	return marshalEnum("Orientation", string(me), orientations)
+}
+
+func (me *Orientation) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("Orientation", data, (*string)(me), orientations)
+}
+
+func (me Page) MarshalJSON() ([]byte, error) {
+	return marshalEnum("Page", string(me), pages)
+}
+
+func (me *Page) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("Page", data, (*string)(me), pages)
+}
+
+// Encodes value as a JSON string in its canonical form, failing with a *ValidationError if it is not one of allowed
+func marshalEnum(field string, value string, allowed []string) ([]byte, error) {
+	if value == "" {
+		return json.Marshal(value)
+	}
+
+	canonical, ok := canonicalValue(value, allowed)
+
+	if !ok {
+		return nil, &ValidationError{Errors: []*FieldError{unknownValue(field, allowed)}}
+	}
+
+	return json.Marshal(canonical)
+}
+
+// Decodes a JSON string into value in its canonical form, failing with a *ValidationError if it is not one of allowed
+func unmarshalEnum(field string, data []byte, value *string, allowed []string) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if s != "" {
+		canonical, ok := canonicalValue(s, allowed)
+
+		if !ok {
+			return &ValidationError{Errors: []*FieldError{unknownValue(field, allowed)}}
+		}
+
+		s = canonical
+	}
+
+	*value = s
+
+	return nil
+}
+
+// Matches value case insensitively and returns the allowed spelling of it, e.g. "fullpage" for "FullPage"
+func canonicalValue(value string, allowed []string) (string, bool) {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return a, true
+		}
+	}
+
+	return "", false
+}
+
+func unknownValue(field string, allowed []string) *FieldError {
+	return &FieldError{Field: field, Message: "must be one of " + strings.Join(allowed, ", ")}
+}
@@ -0,0 +1,73 @@
+package gorestpack
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_Enum_Marshal(t *testing.T) {
+	data, err := json.Marshal(ScreenshotCaptureOptions{Mode: ModeFullPage, Format: FormatJPEG, Wait: WaitNetwork, EmulateMedia: MediaPrint})
+
+	if err != nil || string(data) != `{"mode":"fullpage","format":"jpg","emulate_media":"print","wait":"network"}` {
+		t.Errorf("Must encode constants as plain strings, get: %s, %v", data, err)
+	}
+
+	data, err = json.Marshal(HTMLToPDFCaptureOptions{PDFPage: PageA4, PDFOrientation: OrientationLandscape})
+
+	if err != nil || string(data) != `{"pdf_page":"A4","pdf_orientation":"landscape"}` {
+		t.Errorf("Must encode constants as plain strings, get: %s, %v", data, err)
+	}
+
+	_, err = json.Marshal(ScreenshotCaptureOptions{Mode: "full_page"})
+
+	var fieldErr *FieldError
+	if !errors.Is(err, ErrInvalidOptions) || !errors.As(err, &fieldErr) || fieldErr.Field != "Mode" {
+		t.Errorf("Must reject unknown values, get: %v", err)
+	}
+}
+
+func Test_Enum_Canonical(t *testing.T) {
+	options := ScreenshotCaptureOptions{Mode: "Element", ElementSelector: "#main", Format: "PNG"}
+
+	if err := options.Validate(); err != nil {
+		t.Errorf("Must accept values in any case, get: %v", err)
+	}
+
+	data, err := json.Marshal(options)
+
+	if err != nil || string(data) != `{"mode":"element","format":"png","element_selector":"#main"}` {
+		t.Errorf("Must send canonical values, get: %s, %v", data, err)
+	}
+
+	var decoded HTMLToPDFCaptureOptions
+
+	if err := json.Unmarshal([]byte(`{"pdf_page":"a4","pdf_orientation":"LANDSCAPE"}`), &decoded); err != nil || decoded.PDFPage != PageA4 || decoded.PDFOrientation != OrientationLandscape {
+		t.Errorf("Must decode canonical values, get: %+v, %v", decoded, err)
+	}
+}
+
+func Test_Enum_Unmarshal(t *testing.T) {
+	var options HTMLToPDFCaptureOptions
+
+	if err := json.Unmarshal([]byte(`{"pdf_page":"Letter","wait":"load"}`), &options); err != nil || options.PDFPage != PageLetter || options.Wait != WaitLoad {
+		t.Errorf("Must decode known values, get: %+v, %v", options, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"pdf_orientation":"sideways"}`), &options); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Must reject unknown values, get: %v", err)
+	}
+}
+
+func Test_Enum_WithoutValidation(t *testing.T) {
+	server := newTestServer(t)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithoutValidation())
+
+	if _, err := client.Capture("https://google.com/", ScreenshotCaptureOptions{Format: "gif"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Must not send unknown values, get: %v", err)
+	}
+
+	if len(server.Requests()) != 0 {
+		t.Errorf("Must not send unknown values")
+	}
+}
@@ -0,0 +1,111 @@
+package gorestpack
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// The access token is invalid or not subscribed to any plan.
+	ErrInvalidToken = errors.New("gorestpack: invalid access token")
+	// Too many requests were sent for the current plan.
+	ErrRateLimited = errors.New("gorestpack: rate limited")
+	// The remote page could not be loaded, e.g. DNS failure or a non 2xx response from the remote server.
+	ErrRemoteNavigation = errors.New("gorestpack: remote navigation failed")
+	// Restpack failed to process the request on its side.
+	ErrServer = errors.New("gorestpack: server error")
+)
+
+// Error returned when the Restpack API responds with a non successful status code.
+// Use errors.Is with ErrInvalidToken, ErrRateLimited, ErrRemoteNavigation or ErrServer to classify it.
+type APIError struct {
+	// HTTP status code of the API response.
+	StatusCode int
+	// HTTP status line of the API response, e.g. "400 Bad Request".
+	Status string
+	// Error message returned by the API, if any.
+	Message string
+	// Status code returned by the remote server of the captured page, if any.
+	RemoteStatus string
+	// API endpoint that has been called, e.g. /capture or /convert.
+	Endpoint string
+	// Full URL of the API request.
+	RequestURL string
+	// Number of attempts made before giving up, including retries.
+	Attempts int
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return e.Status
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidToken:
+		return e.StatusCode == http.StatusUnauthorized ||
+			e.StatusCode == http.StatusForbidden ||
+			strings.Contains(e.Message, "access token is invalid")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrRemoteNavigation:
+		return strings.HasPrefix(e.Message, "net::") || remoteFailed(e.RemoteStatus)
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+
+	return false
+}
+
+// Error returned when a request fails without an API response, e.g. on connection errors, once all attempts are made.
+// It unwraps to the error of the last attempt.
+type RequestError struct {
+	// Number of attempts made before giving up, including retries.
+	Attempts int
+	Err      error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func remoteFailed(remoteStatus string) bool {
+	status, err := strconv.Atoi(remoteStatus)
+
+	return err == nil && (status < 200 || status > 299)
+}
+
+func newAPIError(resp *http.Response, body []byte, endpoint string) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Endpoint:   endpoint,
+	}
+
+	if resp.Request != nil {
+		apiErr.RequestURL = resp.Request.URL.String()
+	}
+
+	var res struct {
+		Error        string      `json:"error"`
+		RemoteStatus json.Number `json:"remote_status"`
+	}
+
+	// Binary endpoints may still respond with a JSON error body; anything else is reported by status only
+	if json.Unmarshal(body, &res) == nil {
+		apiErr.Message = res.Error
+		apiErr.RemoteStatus = res.RemoteStatus.String()
+	}
+
+	return apiErr
+}
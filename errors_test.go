@@ -0,0 +1,58 @@
+package gorestpack
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_APIError_Reader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"net::ERR_NAME_NOT_RESOLVED at https://google/"}`))
+	}))
+	defer server.Close()
+
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL))
+	_, err := client.CaptureToReader("https://google/")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Must return *APIError, get: %v", err)
+	}
+
+	if apiErr.StatusCode != 400 || apiErr.Endpoint != "/convert" || apiErr.RequestURL != server.URL+"/api/html2pdf/v5/convert" {
+		t.Errorf("Must fill status and endpoint, get: %+v", apiErr)
+	}
+
+	if err.Error() != "net::ERR_NAME_NOT_RESOLVED at https://google/" {
+		t.Errorf("Must return API error message, get: %s", err.Error())
+	}
+
+	if !errors.Is(err, ErrRemoteNavigation) || errors.Is(err, ErrServer) {
+		t.Errorf("Must only match ErrRemoteNavigation")
+	}
+}
+
+func Test_APIError_Classes(t *testing.T) {
+	cases := []struct {
+		err    *APIError
+		target error
+	}{
+		{&APIError{StatusCode: 401}, ErrInvalidToken},
+		{&APIError{StatusCode: 429}, ErrRateLimited},
+		{&APIError{StatusCode: 502}, ErrServer},
+		{&APIError{StatusCode: 400, RemoteStatus: "404"}, ErrRemoteNavigation},
+	}
+
+	for _, c := range cases {
+		if !errors.Is(c.err, c.target) {
+			t.Errorf("%+v must match %v", c.err, c.target)
+		}
+	}
+
+	if errors.Is(&APIError{StatusCode: 400, RemoteStatus: "200"}, ErrRemoteNavigation) {
+		t.Errorf("Successful remote status must not match ErrRemoteNavigation")
+	}
+}
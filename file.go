@@ -0,0 +1,97 @@
+package gorestpack
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Copies an opened capture stream into w
+func writeStream(w io.Writer, stream io.ReadCloser, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	defer stream.Close()
+
+	return io.Copy(w, stream)
+}
+
+// Resolves the destination of a capture. When path is empty or an existing directory, filename is used as the file name.
+func resolveFilePath(path string, filename string) (string, error) {
+	if path != "" {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return path, nil
+		}
+	}
+
+	if filename == "" {
+		return "", errors.New("gorestpack: no file path or Filename option supplied")
+	}
+
+	return filepath.Join(path, filepath.Base(filename)), nil
+}
+
+// Writes an opened capture stream to path atomically: the data goes to a temporary file in the same directory
+// which is renamed over path once complete, and removed if anything fails.
+// The file gets the mode of the file it replaces, or 0644 for a new file.
+func writeStreamToFile(path string, filename string, open func() (io.ReadCloser, error)) (string, error) {
+	path, err := resolveFilePath(path, filename)
+
+	if err != nil {
+		return "", err
+	}
+
+	stream, err := open()
+
+	if err != nil {
+		return "", err
+	}
+
+	defer stream.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+
+	if err != nil {
+		return "", err
+	}
+
+	mode := os.FileMode(0644)
+
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	if err := copyToFile(tmp, stream); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return path, nil
+}
+
+func copyToFile(file *os.File, stream io.Reader) error {
+	if _, err := io.Copy(file, stream); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,96 @@
+package gorestpack
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_File_CaptureToFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("%PDF-1.4 test"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL))
+
+	path, err := client.CaptureToFile(dir, "https://google.com/", HTMLToPDFCaptureOptions{Filename: "invoice.pdf"})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if path != filepath.Join(dir, "invoice.pdf") {
+		t.Errorf("Must use Filename option inside directory, get: %s", path)
+	}
+
+	body, err := os.ReadFile(path)
+
+	if err != nil || !Pdf(body) {
+		t.Errorf("Must write pdf file, get: %v", err)
+	}
+
+	files, _ := os.ReadDir(dir)
+
+	if len(files) != 1 {
+		t.Errorf("Must not leave temporary files, get: %d files", len(files))
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("Must create readable file, get: %v", info.Mode())
+	}
+
+	os.Chmod(path, 0600)
+
+	if _, err := client.CaptureToFile(path, "https://google.com/"); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Must keep mode of replaced file, get: %v", info.Mode())
+	}
+}
+
+func Test_File_CaptureToFile_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL))
+
+	if _, err := client.CaptureHTMLToFile(filepath.Join(dir, "out.png"), "<h1>Test</h1>"); err == nil {
+		t.Errorf("Must return error")
+	}
+
+	files, _ := os.ReadDir(dir)
+
+	if len(files) != 0 {
+		t.Errorf("Must not leave partial output, get: %d files", len(files))
+	}
+}
+
+func Test_File_CaptureToWriter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0x89, 0x50, 0x4E, 0x47, 0x0D})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL))
+
+	n, err := client.CaptureToWriter(&buf, "https://google.com/")
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if n != 5 || !Png(buf.Bytes()) {
+		t.Errorf("Must write png file, get: %d bytes", n)
+	}
+}
@@ -0,0 +1,113 @@
+package gorestpack
+
+import (
+	"context"
+	"reflect"
+)
+
+// Capture running in the background, started by a CaptureAsync method
+type Future interface {
+	// Closed once the capture completes, fails or is cancelled
+	Done() <-chan struct{}
+	// Abort the capture. The future completes with context.Canceled unless it is already done.
+	Cancel()
+	// Error of the capture once done, nil before
+	Err() error
+}
+
+// Shared state of ScreenshotFuture and HTMLToPDFFuture
+type future struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	err    error
+}
+
+// Runs capture in the background. ctx is passed on to capture and cancelled by Cancel.
+func startFuture(ctx context.Context, capture func(ctx context.Context) error) *future {
+	ctx, cancel := context.WithCancel(ctx)
+	me := &future{done: make(chan struct{}), cancel: cancel}
+
+	go func() {
+		defer close(me.done)
+		defer cancel()
+
+		me.err = capture(ctx)
+	}()
+
+	return me
+}
+
+func (me *future) Done() <-chan struct{} {
+	return me.done
+}
+
+func (me *future) Cancel() {
+	me.cancel()
+}
+
+func (me *future) Err() error {
+	select {
+	case <-me.done:
+		return me.err
+	default:
+		return nil
+	}
+}
+
+// Waits for the future to complete. Giving up on ctx does not cancel the capture.
+func (me *future) wait(ctx context.Context) error {
+	select {
+	case <-me.done:
+		return me.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait until all futures complete. If any capture failed, a *BatchError summarizing the failures is returned.
+// Returns ctx.Err() if ctx is cancelled first, leaving the captures running.
+func WaitAll(ctx context.Context, futures ...Future) error {
+	batchErr := &BatchError{Total: len(futures)}
+
+	for _, f := range futures {
+		select {
+		case <-f.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if err := f.Err(); err != nil {
+			batchErr.Failed++
+			batchErr.Errors = append(batchErr.Errors, err)
+		}
+	}
+
+	if batchErr.Failed > 0 {
+		return batchErr
+	}
+
+	return nil
+}
+
+// Wait until any of the futures completes and return its index.
+// Returns -1 and ctx.Err() if ctx is cancelled first.
+func WaitAny(ctx context.Context, futures ...Future) (int, error) {
+	if len(futures) == 0 {
+		return -1, nil
+	}
+
+	cases := make([]reflect.SelectCase, len(futures)+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+
+	for i, f := range futures {
+		cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(f.Done())}
+	}
+
+	chosen, _, _ := reflect.Select(cases)
+
+	if chosen == 0 {
+		return -1, ctx.Err()
+	}
+
+	return chosen - 1, nil
+}
@@ -0,0 +1,90 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Future_Wait(t *testing.T) {
+	server := newTestServer(t)
+	client := NewHTMLToPDFClient(testToken, WithBaseURL(server.URL))
+
+	f := client.CaptureHTMLAsync(context.Background(), "<h1>Invoice</h1>")
+	res, err := f.Wait(context.Background())
+
+	if err != nil || res.Image == "" {
+		t.Errorf("Must return capture result, get: %v", err)
+	}
+
+	select {
+	case <-f.Done():
+	default:
+		t.Errorf("Must be done after Wait")
+	}
+}
+
+func Test_Future_Cancel(t *testing.T) {
+	server := newTestServer(t)
+	server.SetLatency(time.Second)
+
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL))
+	f := client.CaptureAsync(context.Background(), "https://google.com/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := f.Wait(ctx); err != context.DeadlineExceeded || f.Err() != nil {
+		t.Errorf("Must stop waiting without cancelling the capture, get: %v", err)
+	}
+
+	f.Cancel()
+
+	if _, err := f.Wait(context.Background()); err != context.Canceled {
+		t.Errorf("Must abort cancelled capture, get: %v", err)
+	}
+}
+
+func Test_Future_WaitAll(t *testing.T) {
+	server := newTestServer(t)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithLimiter(NewRateLimiter(RateLimiterConfig{MaxInFlight: 1})))
+
+	ok := client.CaptureAsync(context.Background(), "https://google.com/")
+	failed := client.CaptureAsync(context.Background(), "https://google/")
+
+	err := WaitAll(context.Background(), ok, failed)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed != 1 || batchErr.Total != 2 || !errors.Is(err, ErrRemoteNavigation) {
+		t.Errorf("Must summarize failures, get: %v", err)
+	}
+
+	if res, err := ok.Wait(context.Background()); err != nil || res.Image == "" {
+		t.Errorf("Must keep successful result, get: %v", err)
+	}
+}
+
+func Test_Future_WaitAny(t *testing.T) {
+	server := newTestServer(t)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := client.CaptureAsync(ctx, "https://google.com/")
+	cancel()
+
+	if i, err := WaitAny(context.Background(), cancelled); i != 0 || err != nil || !errors.Is(cancelled.Err(), context.Canceled) {
+		t.Errorf("Must return completed future, get: %d, %v", i, err)
+	}
+
+	server.SetLatency(time.Second)
+	pending := client.CaptureAsync(context.Background(), "https://google.com/")
+	defer pending.Cancel()
+
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+
+	if i, err := WaitAny(timeout, pending); i != -1 || err != context.DeadlineExceeded {
+		t.Errorf("Must return context error, get: %d, %v", i, err)
+	}
+}
@@ -0,0 +1,239 @@
+package gorestpacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Mode of a Recorder
+type Mode int
+
+const (
+	// Serve responses from the cassette and fail on requests that were not recorded
+	ModeReplay Mode = iota
+	// Send requests to the real transport and record them into the cassette
+	ModeRecord
+)
+
+// Value stored in place of the access token
+const redactedToken = "REDACTED"
+
+// Recorded request and response pair
+type Interaction struct {
+	Method   string           `json:"method"`
+	Endpoint string           `json:"endpoint"`
+	Options  json.RawMessage  `json:"options"`
+	Header   http.Header      `json:"header"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Recorded API response
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// Cassette style http.RoundTripper recording Restpack API interactions to a file and replaying them.
+// Requests are matched on method, endpoint path and canonicalized JSON capture options, regardless of the API host.
+// Access tokens are never written to the cassette.
+//
+// Use it with gorestpack.WithTransport:
+//
+//	recorder, err := gorestpacktest.NewRecorder("testdata/capture.json", gorestpacktest.ModeReplay)
+//	client := gorestpack.NewScreenshotClient(token, gorestpack.WithTransport(recorder))
+//	...
+//	recorder.Save() // in ModeRecord
+type Recorder struct {
+	// Transport used to send requests in ModeRecord. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	path string
+	mode Mode
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// Create a new Recorder for the cassette at path. In ModeReplay the cassette is loaded and must exist.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	recorder := &Recorder{path: path, mode: mode}
+
+	if mode != ModeReplay {
+		return recorder, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &recorder.interactions); err != nil {
+		return nil, fmt.Errorf("gorestpacktest: invalid cassette %s: %v", path, err)
+	}
+
+	for i, interaction := range recorder.interactions {
+		// Cassettes are stored indented, so options are canonicalized again for matching
+		if recorder.interactions[i].Options, err = canonicalJSON(interaction.Options); err != nil {
+			return nil, fmt.Errorf("gorestpacktest: invalid cassette %s: %v", path, err)
+		}
+	}
+
+	recorder.used = make([]bool, len(recorder.interactions))
+
+	return recorder, nil
+}
+
+func (me *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	options, err := canonicalJSON(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("gorestpacktest: request body is not JSON: %v", err)
+	}
+
+	if me.mode == ModeReplay {
+		return me.replay(req, options)
+	}
+
+	return me.record(req, body, options)
+}
+
+// Write the recorded interactions to the cassette file
+func (me *Recorder) Save() error {
+	me.mu.Lock()
+	data, err := json.MarshalIndent(me.interactions, "", "  ")
+	me.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(me.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(me.path, data, 0644)
+}
+
+func (me *Recorder) replay(req *http.Request, options []byte) (*http.Response, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	match := -1
+
+	// Identical requests are answered in recording order; the last match is repeated once all are used
+	for i, interaction := range me.interactions {
+		if interaction.Method != req.Method || interaction.Endpoint != req.URL.Path || !bytes.Equal(interaction.Options, options) {
+			continue
+		}
+
+		match = i
+
+		if !me.used[i] {
+			break
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("gorestpacktest: no recorded interaction in %s for %s %s %s", me.path, req.Method, req.URL.Path, options)
+	}
+
+	me.used[match] = true
+	recorded := me.interactions[match].Response
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (me *Recorder) record(req *http.Request, body []byte, options []byte) (*http.Response, error) {
+	transport := me.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	resp, err := transport.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	header := req.Header.Clone()
+	if header.Get("x-access-token") != "" {
+		header.Set("x-access-token", redactedToken)
+	}
+
+	me.mu.Lock()
+	me.interactions = append(me.interactions, Interaction{
+		Method:   req.Method,
+		Endpoint: req.URL.Path,
+		Options:  options,
+		Header:   header,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       data,
+		},
+	})
+	me.mu.Unlock()
+
+	return resp, nil
+}
+
+// Re-encodes a JSON document with sorted object keys and no insignificant whitespace
+func canonicalJSON(data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []byte("null"), nil
+	}
+
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
@@ -0,0 +1,76 @@
+package gorestpacktest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func send(t *testing.T, transport http.RoundTripper, url string, body string) (*http.Response, error) {
+	req, _ := http.NewRequest("POST", url, bytes.NewReader([]byte(body)))
+	req.Header.Set("x-access-token", "SECRET_TOKEN")
+
+	return (&http.Client{Transport: transport}).Do(req)
+}
+
+func Test_Recorder_RecordReplay(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "capture.json")
+
+	recorder, err := NewRecorder(path, ModeRecord)
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	resp, err := send(t, recorder, server.URL+"/api/html2pdf/v5/convert", `{"url":"https://google.com/","pdf_page":"A4"}`)
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	recorded, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	cassette, _ := os.ReadFile(path)
+
+	if strings.Contains(string(cassette), "SECRET_TOKEN") {
+		t.Errorf("Must redact access token")
+	}
+
+	server.Close()
+
+	replayer, err := NewRecorder(path, ModeReplay)
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	// Key order and host differ from the recorded request
+	resp, err = send(t, replayer, "https://restpack.io/api/html2pdf/v5/convert", `{"pdf_page": "A4", "url": "https://google.com/"}`)
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	replayed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !bytes.Equal(recorded, replayed) || resp.StatusCode != http.StatusOK {
+		t.Errorf("Must replay recorded response")
+	}
+
+	if _, err := send(t, replayer, "https://restpack.io/api/html2pdf/v5/convert", `{"url":"https://example.com/"}`); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("Must fail on unmatched request, get: %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+// Package gorestpacktest provides a local fake of the Restpack API for offline tests.
+//
+// Point a client at it with gorestpack.WithBaseURL:
+//
+//	server := gorestpacktest.NewServer()
+//	defer server.Close()
+//
+//	client := gorestpack.NewScreenshotClient("TOKEN", gorestpack.WithBaseURL(server.URL))
+package gorestpacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error message returned for invalid access tokens, as sent by the Restpack API
+const InvalidTokenMessage = "The access token is invalid or you are not subscribed to any plan. Please visit the API console and choose your subscription plan."
+
+// Failure the server responds with instead of a capture
+type Failure int
+
+const (
+	// 401 with the invalid access token message
+	FailInvalidToken Failure = iota + 1
+	// 400 with a net::ERR_NAME_NOT_RESOLVED navigation error
+	FailNavigation
+	// 429 Too Many Requests
+	FailRateLimit
+	// 500 Internal Server Error
+	FailServer
+	// 503 Service Unavailable
+	FailUnavailable
+)
+
+// API request received by the server
+type Request struct {
+	// Restpack service called, screenshot or html2pdf.
+	Service string
+	// API endpoint, /capture or /convert.
+	Endpoint string
+	// Access token sent with the request.
+	Token string
+	// Headers sent with the request.
+	Header http.Header
+	// Raw JSON body of the request.
+	Body []byte
+	// Capture options decoded from the body.
+	Options map[string]interface{}
+}
+
+// URL being captured, empty for HTML captures
+func (r Request) URL() string {
+	value, _ := r.Options["url"].(string)
+	return value
+}
+
+// HTML snippet being captured, empty for URL captures
+func (r Request) HTML() string {
+	value, _ := r.Options["html"].(string)
+	return value
+}
+
+// Fake Restpack API serving /api/screenshot/v5/capture and /api/html2pdf/v5/convert
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	tokens   map[string]bool
+	latency  time.Duration
+	failures []Failure
+	requests []Request
+	seen     map[string]bool
+	files    map[string][]byte
+}
+
+// Start a new Server. If tokens are supplied, only those are accepted as valid access tokens,
+// otherwise any non empty token is.
+func NewServer(tokens ...string) *Server {
+	server := &Server{
+		tokens: map[string]bool{},
+		seen:   map[string]bool{},
+		files:  map[string][]byte{},
+	}
+
+	for _, token := range tokens {
+		server.tokens[token] = true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/screenshot/v5/capture", server.handle("screenshot", "/capture"))
+	mux.HandleFunc("/api/html2pdf/v5/convert", server.handle("html2pdf", "/convert"))
+	mux.HandleFunc("/cdn/", server.serveFile)
+
+	server.Server = httptest.NewServer(mux)
+
+	return server
+}
+
+// Delay every response by latency
+func (me *Server) SetLatency(latency time.Duration) {
+	me.mu.Lock()
+	me.latency = latency
+	me.mu.Unlock()
+}
+
+// Respond to the next requests with failures, one per request in the given order
+func (me *Server) FailNext(failures ...Failure) {
+	me.mu.Lock()
+	me.failures = append(me.failures, failures...)
+	me.mu.Unlock()
+}
+
+// Requests received so far
+func (me *Server) Requests() []Request {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return append([]Request(nil), me.requests...)
+}
+
+// Forget the requests received so far
+func (me *Server) Reset() {
+	me.mu.Lock()
+	me.requests = nil
+	me.seen = map[string]bool{}
+	me.mu.Unlock()
+}
+
+func (me *Server) handle(service string, endpoint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		req := Request{
+			Service:  service,
+			Endpoint: endpoint,
+			Token:    r.Header.Get("x-access-token"),
+			Header:   r.Header.Clone(),
+			Body:     body,
+		}
+
+		if err := json.Unmarshal(body, &req.Options); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+
+		me.mu.Lock()
+		me.requests = append(me.requests, req)
+		latency := me.latency
+		var failure Failure
+		if len(me.failures) > 0 {
+			failure = me.failures[0]
+			me.failures = me.failures[1:]
+		}
+		validToken := req.Token != "" && (len(me.tokens) == 0 || me.tokens[req.Token])
+		cached := me.seen[string(body)]
+		me.seen[string(body)] = true
+		me.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if !validToken && failure == 0 {
+			failure = FailInvalidToken
+		}
+
+		if failure == 0 && req.URL() != "" && !resolvable(req.URL()) {
+			failure = FailNavigation
+		}
+
+		if failure == 0 && req.URL() == "" && req.HTML() == "" {
+			writeError(w, http.StatusBadRequest, "Either url or html must be supplied")
+			return
+		}
+
+		switch failure {
+		case FailInvalidToken:
+			writeError(w, http.StatusUnauthorized, InvalidTokenMessage)
+			return
+		case FailNavigation:
+			writeError(w, http.StatusBadRequest, "net::ERR_NAME_NOT_RESOLVED at "+req.URL())
+			return
+		case FailRateLimit:
+			writeError(w, http.StatusTooManyRequests, "Too many requests")
+			return
+		case FailServer:
+			writeError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		case FailUnavailable:
+			writeError(w, http.StatusServiceUnavailable, "Service unavailable")
+			return
+		}
+
+		data, contentType, ext := render(service, req.Options)
+
+		if asJSON, _ := req.Options["json"].(bool); !asJSON {
+			w.Header().Set("Content-Type", contentType)
+			w.Write(data)
+			return
+		}
+
+		me.mu.Lock()
+		name := strconv.Itoa(len(me.files)+1) + ext
+		me.files[name] = data
+		me.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"image":         me.URL + "/cdn/" + name,
+			"width":         strconv.Itoa(intOption(req.Options, "width", 1280)),
+			"height":        strconv.Itoa(intOption(req.Options, "height", 1024)),
+			"remote_status": "200",
+			"cached":        strconv.FormatBool(cached),
+			"url":           req.URL(),
+		})
+	}
+}
+
+func (me *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	me.mu.Lock()
+	data, ok := me.files[strings.TrimPrefix(r.URL.Path, "/cdn/")]
+	me.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// Common top level domains considered resolvable, so that e.g. https://google/ fails like it does against the live API
+var knownTLDs = map[string]bool{
+	"com": true, "net": true, "org": true, "io": true, "dev": true, "app": true, "co": true,
+	"edu": true, "gov": true, "info": true, "biz": true, "me": true, "uk": true, "de": true,
+	"fr": true, "nl": true, "tr": true, "us": true, "eu": true, "test": true, "example": true,
+}
+
+func resolvable(rawurl string) bool {
+	u, err := url.Parse(rawurl)
+
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+
+	host := u.Hostname()
+
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return true
+	}
+
+	labels := strings.Split(host, ".")
+
+	return len(labels) > 1 && knownTLDs[labels[len(labels)-1]]
+}
+
+func intOption(options map[string]interface{}, name string, fallback int) int {
+	if value, ok := options[name].(float64); ok && value > 0 {
+		return int(value)
+	}
+
+	return fallback
+}
+
+// Renders a placeholder output: a PDF document for html2pdf, otherwise an image in the requested format
+func render(service string, options map[string]interface{}) ([]byte, string, string) {
+	if service == "html2pdf" {
+		return pdfDocument(), "application/pdf", ".pdf"
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 16; y++ {
+			img.Set(x, y, color.RGBA{R: 0x33, G: 0x66, B: 0x99, A: 0xff})
+		}
+	}
+
+	var buf bytes.Buffer
+
+	switch format, _ := options["format"].(string); format {
+	case "jpg", "jpeg":
+		jpeg.Encode(&buf, img, nil)
+		return buf.Bytes(), "image/jpeg", ".jpg"
+	case "html":
+		return []byte("<html><body></body></html>"), "text/html", ".html"
+	default:
+		png.Encode(&buf, img)
+		return buf.Bytes(), "image/png", ".png"
+	}
+}
+
+// Builds a minimal single page PDF document with a valid cross reference table
+func pdfDocument() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,111 @@
+package gorestpacktest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"net/http"
+	"testing"
+	"time"
+
+	_ "image/jpeg"
+)
+
+func post(t *testing.T, url string, token string, body string) *http.Response {
+	req, _ := http.NewRequest("POST", url, bytes.NewReader([]byte(body)))
+	req.Header.Set("x-access-token", token)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	return resp
+}
+
+func Test_Server_Capture(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	resp := post(t, server.URL+"/api/screenshot/v5/capture", "TOKEN", `{"url":"https://google.com/","format":"jpg"}`)
+	defer resp.Body.Close()
+
+	if _, format, err := image.Decode(resp.Body); err != nil || format != "jpeg" {
+		t.Errorf("Must return jpeg image, get: %s, %v", format, err)
+	}
+
+	requests := server.Requests()
+
+	if len(requests) != 1 || requests[0].Service != "screenshot" || requests[0].Token != "TOKEN" || requests[0].URL() != "https://google.com/" {
+		t.Errorf("Must record request, get: %+v", requests)
+	}
+}
+
+func Test_Server_JSON_Cached(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	for i, expected := range []string{"false", "true"} {
+		resp := post(t, server.URL+"/api/html2pdf/v5/convert", "TOKEN", `{"html":"<h1>Test</h1>","json":true}`)
+
+		var res map[string]string
+		json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+
+		if res["cached"] != expected || res["url"] != "" {
+			t.Errorf("Request %d must return cached %s, get: %v", i, expected, res)
+		}
+
+		file, err := http.Get(res["image"])
+
+		if err != nil || file.StatusCode != http.StatusOK {
+			t.Fatalf("Must serve result file, get: %v", err)
+		}
+
+		file.Body.Close()
+	}
+}
+
+func Test_Server_Failures(t *testing.T) {
+	server := NewServer("TOKEN")
+	defer server.Close()
+
+	server.FailNext(FailRateLimit, FailServer)
+
+	for _, expected := range []int{429, 500, 401, 400} {
+		token, url := "TOKEN", "https://google.com/"
+
+		switch expected {
+		case 401:
+			token = "OTHER"
+		case 400:
+			url = "https://google/"
+		}
+
+		resp := post(t, server.URL+"/api/screenshot/v5/capture", token, `{"url":"`+url+`"}`)
+		resp.Body.Close()
+
+		if resp.StatusCode != expected {
+			t.Errorf("Must respond %d, get: %d", expected, resp.StatusCode)
+		}
+	}
+}
+
+func Test_Server_Latency(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequest("POST", server.URL+"/api/screenshot/v5/capture", bytes.NewReader([]byte(`{"url":"https://google.com/"}`)))
+	req.Header.Set("x-access-token", "TOKEN")
+
+	if _, err := http.DefaultClient.Do(req.WithContext(ctx)); err == nil {
+		t.Errorf("Must delay response")
+	}
+}
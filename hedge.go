@@ -0,0 +1,203 @@
+package gorestpack
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Number of recent latencies kept to compute HedgePolicy.Percentile
+const hedgeSamples = 100
+
+// Minimum number of latencies recorded before HedgePolicy.Percentile is used
+const minHedgeSamples = 20
+
+// Configuration of hedged requests. A duplicate of a slow request is sent and whichever responds first is used,
+// the other one is cancelled.
+type HedgePolicy struct {
+	// Time to wait for a response before sending the duplicate request. Zero disables hedging until Percentile applies.
+	Delay time.Duration
+	// Send the duplicate request once the wait exceeds this percentile of recent latencies, e.g. 0.95.
+	// Values above 1 are read as percentages, e.g. 95, and capped at 100. Delay is used until enough latencies are recorded.
+	Percentile float64
+	// Maximum share of requests that may be duplicated, keeping the extra quota spent bounded. Defaults to 0.1.
+	Budget float64
+}
+
+// Hedge requests according to policy to cut tail latency
+func WithHedging(policy HedgePolicy) Option {
+	return func(cfg *config) {
+		if policy.Budget <= 0 {
+			policy.Budget = 0.1
+		}
+
+		if policy.Percentile > 1 {
+			policy.Percentile = math.Min(policy.Percentile/100, 1)
+		}
+
+		cfg.hedgePolicy = &policy
+	}
+}
+
+type hedger struct {
+	policy HedgePolicy
+
+	mu       sync.Mutex
+	samples  []time.Duration
+	next     int
+	requests int
+	hedges   int
+}
+
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+func newHedger(policy HedgePolicy) *hedger {
+	return &hedger{policy: policy}
+}
+
+// Runs attempt and, if it is slow, a duplicate of it. Returns the first response received.
+// The context of the returned response is cancelled once its body is closed.
+func (me *hedger) run(ctx context.Context, attempt func(ctx context.Context) (*http.Response, error)) (*http.Response, error) {
+	me.mu.Lock()
+	me.requests++
+	delay, ok := me.delay()
+	me.mu.Unlock()
+
+	start := time.Now()
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+
+	launch := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+
+		go func() {
+			resp, err := attempt(attemptCtx)
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	launch()
+
+	var timeout <-chan time.Time
+
+	if ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var firstErr error
+
+	for received := 0; received < len(cancels); {
+		select {
+		case <-timeout:
+			timeout = nil
+
+			if me.spend() {
+				launch()
+			}
+		case result := <-results:
+			received++
+
+			if result.err != nil {
+				if firstErr == nil {
+					firstErr = result.err
+				}
+				continue
+			}
+
+			me.record(time.Since(start))
+
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+
+			go discard(results, len(cancels)-received)
+
+			result.resp.Body = &cancelBody{ReadCloser: result.resp.Body, cancel: cancels[result.index]}
+			return result.resp, nil
+		}
+	}
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+
+	return nil, firstErr
+}
+
+// Delay before sending a duplicate request. Must be called with the lock held.
+func (me *hedger) delay() (time.Duration, bool) {
+	if me.policy.Percentile > 0 && len(me.samples) >= minHedgeSamples {
+		sorted := append([]time.Duration(nil), me.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		index := int(me.policy.Percentile * float64(len(sorted)-1))
+		if index > len(sorted)-1 {
+			index = len(sorted) - 1
+		}
+
+		return sorted[index], true
+	}
+
+	return me.policy.Delay, me.policy.Delay > 0
+}
+
+// Reports whether the budget allows another duplicate request and accounts for it
+func (me *hedger) spend() bool {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	if float64(me.hedges+1) > me.policy.Budget*float64(me.requests) {
+		return false
+	}
+
+	me.hedges++
+	return true
+}
+
+func (me *hedger) record(latency time.Duration) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	if len(me.samples) < hedgeSamples {
+		me.samples = append(me.samples, latency)
+		return
+	}
+
+	me.samples[me.next] = latency
+	me.next = (me.next + 1) % hedgeSamples
+}
+
+// Closes the responses of cancelled duplicate requests as they come in
+func discard(results chan hedgeResult, pending int) {
+	for i := 0; i < pending; i++ {
+		if result := <-results; result.resp != nil {
+			result.resp.Body.Close()
+		}
+	}
+}
+
+// Response body cancelling the context of its request once closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (me *cancelBody) Close() error {
+	err := me.ReadCloser.Close()
+	me.cancel()
+	return err
+}
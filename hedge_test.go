@@ -0,0 +1,112 @@
+package gorestpack
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Middleware delaying the first request sent by a client
+func slowFirstRequest(delay time.Duration, cancelled *int32) Middleware {
+	var calls int32
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, r *Request) (*http.Response, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					atomic.AddInt32(cancelled, 1)
+					return nil, ctx.Err()
+				}
+			}
+
+			return next(ctx, r)
+		}
+	}
+}
+
+func Test_Hedging_FirstResponseWins(t *testing.T) {
+	server := newTestServer(t)
+
+	var cancelled int32
+	client := NewScreenshotClient(testToken,
+		WithBaseURL(server.URL),
+		WithMiddleware(slowFirstRequest(time.Second, &cancelled)),
+		WithHedging(HedgePolicy{Delay: 20 * time.Millisecond, Budget: 1}),
+	)
+
+	start := time.Now()
+	img, err := client.CaptureToImage("https://google.com/")
+
+	if err != nil || img == nil {
+		t.Fatalf("Must capture, get: %v", err)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Must use the hedged response, took: %v", time.Since(start))
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if atomic.LoadInt32(&cancelled) != 1 {
+		t.Errorf("Must cancel the slow request")
+	}
+}
+
+func Test_Hedging_Budget(t *testing.T) {
+	server := newTestServer(t)
+	server.SetLatency(30 * time.Millisecond)
+
+	client := NewHTMLToPDFClient(testToken,
+		WithBaseURL(server.URL),
+		WithHedging(HedgePolicy{Delay: 5 * time.Millisecond, Budget: 0.25}),
+	)
+
+	for i := 0; i < 8; i++ {
+		if _, err := client.CaptureToReader("https://google.com/"); err != nil {
+			t.Fatalf("Must capture, get: %v", err)
+		}
+	}
+
+	if n := len(server.Requests()); n != 10 {
+		t.Errorf("Must cap duplicate requests to the budget, get: %d requests", n)
+	}
+}
+
+func Test_Hedger_Percentile(t *testing.T) {
+	hedger := newHedger(HedgePolicy{Delay: time.Second, Percentile: 0.9})
+
+	if delay, ok := hedger.delay(); !ok || delay != time.Second {
+		t.Errorf("Must use Delay until enough latencies are recorded, get: %v", delay)
+	}
+
+	for i := 1; i <= 100; i++ {
+		hedger.record(time.Duration(i) * time.Millisecond)
+	}
+
+	if delay, ok := hedger.delay(); !ok || delay != 90*time.Millisecond {
+		t.Errorf("Must use latency percentile, get: %v", delay)
+	}
+}
+
+func Test_Hedging_PercentileRange(t *testing.T) {
+	cfg := newConfig([]Option{WithHedging(HedgePolicy{Percentile: 95})})
+
+	if cfg.hedgePolicy.Percentile != 0.95 {
+		t.Errorf("Must read percentile above 1 as percentage, get: %v", cfg.hedgePolicy.Percentile)
+	}
+
+	cfg = newConfig([]Option{WithHedging(HedgePolicy{Percentile: 250})})
+	hedger := newHedger(*cfg.hedgePolicy)
+
+	for i := 1; i <= 30; i++ {
+		hedger.record(time.Duration(i) * time.Millisecond)
+	}
+
+	if delay, ok := hedger.delay(); !ok || delay != 30*time.Millisecond {
+		t.Errorf("Must cap percentile at the slowest latency, get: %v", delay)
+	}
+}
@@ -0,0 +1,42 @@
+package gorestpack
+
+import (
+	"os"
+	"testing"
+
+	"github.com/restpackio/gorestpack/gorestpacktest"
+)
+
+// Token accepted by the fake server when no live API token is configured
+const testToken = "TEST_TOKEN"
+
+// Screenshot client for tests, running against the live API when SS_TOKEN is set and against gorestpacktest otherwise
+func newTestScreenshotClient(t *testing.T, token string) ScreenshotClient {
+	if os.Getenv("SS_TOKEN") != "" {
+		return NewScreenshotClient(token)
+	}
+
+	return NewScreenshotClient(fakeToken(token), WithBaseURL(newTestServer(t).URL))
+}
+
+// HTML to PDF client for tests, running against the live API when PDF_TOKEN is set and against gorestpacktest otherwise
+func newTestHTMLToPDFClient(t *testing.T, token string) HTMLToPDFClient {
+	if os.Getenv("PDF_TOKEN") != "" {
+		return NewHTMLToPDFClient(token)
+	}
+
+	return NewHTMLToPDFClient(fakeToken(token), WithBaseURL(newTestServer(t).URL))
+}
+
+func newTestServer(t *testing.T) *gorestpacktest.Server {
+	server := gorestpacktest.NewServer(testToken)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fakeToken(token string) string {
+	if token == "" {
+		return testToken
+	}
+	return token
+}
@@ -2,31 +2,23 @@ package gorestpack
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"io"
-
-	"github.com/eknkc/request"
 )
 
-// Create a new Screenshot Client with supplied restpack.io access key
-func NewHTMLToPDFClient(accessToken string) HTMLToPDFClient {
-	return &htmlToPDFClient{
-		client: &client{
-			httpClient:  request.New(),
-			accessToken: accessToken,
-			basePath:    "https://restpack.io/api/html2pdf/v5",
-		},
-	}
+// Create a new HTML to PDF Client with supplied restpack.io access key and optional client options
+func NewHTMLToPDFClient(accessToken string, options ...Option) HTMLToPDFClient {
+	return New(accessToken, options...).HTMLToPDF()
 }
 
 // Options supplied to the Restpack Screenshot API for conversion
 type HTMLToPDFCaptureOptions struct {
 	// Custom page size for created document
-	PDFPage string `json:"pdf_page,omitempty"`
+	PDFPage Page `json:"pdf_page,omitempty"`
 	// CSS style margin sizes.
 	PDFMargins string `json:"pdf_margins,omitempty"`
 	// Page Orientation
-	PDFOrientation string `json:"pdf_orientation,omitempty"`
+	PDFOrientation Orientation `json:"pdf_orientation,omitempty"`
 	// Additional CSS string to be injected into the page before render.
 	CSS string `json:"css,omitempty"`
 	// Additional JS string to be injected into the page before render.
@@ -42,11 +34,11 @@ type HTMLToPDFCaptureOptions struct {
 	// Additional headers seperated with newline
 	Headers string `json:"headers,omitempty"`
 	// Force CSS media emulation for print or screen.
-	EmulateMedia string `json:"emulate_media,omitempty"`
+	EmulateMedia Media `json:"emulate_media,omitempty"`
 	// By default, any response from remote server outside http 200-299 status codes generates an error. If you wish to capture error pages, pass true.
 	AllowFailed bool `json:"allow_failed,omitempty"`
 	// Wait until window load event fires or network becomes idle before capturing the page.
-	Wait string `json:"wait,omitempty"`
+	Wait Wait `json:"wait,omitempty"`
 	// Wait until a DOM element matching the provided css selector becomes present on the page.
 	Shutter string `json:"shutter,omitempty"`
 	// Ensure that the captured document does not get cached / stored for further use
@@ -74,6 +66,11 @@ type htmlToPDFCallOptions struct {
 	HTML string `json:"html,omitempty"`
 }
 
+// Private captures are never stored in the client's Cache
+func (me htmlToPDFCallOptions) cacheable() bool {
+	return !me.Privacy
+}
+
 // Capture result from screenshot API
 type HTMLToPDFCaptureResult struct {
 	Image        string `json:"image,omitempty"`
@@ -82,6 +79,37 @@ type HTMLToPDFCaptureResult struct {
 	RemoteStatus string `json:"remote_status,omitempty"`
 	Cached       bool   `json:"cached,string,omitempty"`
 	URL          string `json:"url,omitempty"`
+	// Number of API requests made to obtain the result, including retries
+	Attempts int `json:"-"`
+}
+
+// Single capture of a batch. Either URL or HTML must be set
+type HTMLToPDFRequest struct {
+	URL     string
+	HTML    string
+	Options HTMLToPDFCaptureOptions
+}
+
+// Outcome of a single capture of a batch
+type HTMLToPDFBatchResult struct {
+	Request HTMLToPDFRequest
+	Result  HTMLToPDFCaptureResult
+	Err     error
+}
+
+// HTML to PDF conversion running in the background
+type HTMLToPDFFuture struct {
+	*future
+	result HTMLToPDFCaptureResult
+}
+
+// Wait for the capture to complete and return its result. Giving up on ctx does not cancel the capture.
+func (me *HTMLToPDFFuture) Wait(ctx context.Context) (HTMLToPDFCaptureResult, error) {
+	if err := me.wait(ctx); err != nil {
+		return HTMLToPDFCaptureResult{}, err
+	}
+
+	return me.result, nil
 }
 
 // Restpack Screenshot API Client
@@ -95,6 +123,52 @@ type HTMLToPDFClient interface {
 	CaptureToReader(url string, options ...HTMLToPDFCaptureOptions) (io.Reader, error)
 	// Capture a HTML snippet and returna a reader for resulting pdf
 	CaptureHTMLToReader(url string, options ...HTMLToPDFCaptureOptions) (io.Reader, error)
+
+	// Same as Capture, aborting the request when ctx is cancelled
+	CaptureContext(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) (HTMLToPDFCaptureResult, error)
+	// Same as CaptureHTML, aborting the request when ctx is cancelled
+	CaptureHTMLContext(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) (HTMLToPDFCaptureResult, error)
+	// Same as CaptureToReader, aborting the request when ctx is cancelled
+	CaptureToReaderContext(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) (io.Reader, error)
+	// Same as CaptureHTMLToReader, aborting the request when ctx is cancelled
+	CaptureHTMLToReaderContext(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) (io.Reader, error)
+
+	// Capture a URL and return the resulting pdf streamed from the API response. The stream must be closed by the caller
+	CaptureToStream(url string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error)
+	// Capture a HTML snippet and return the resulting pdf streamed from the API response. The stream must be closed by the caller
+	CaptureHTMLToStream(html string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error)
+	// Same as CaptureToStream, aborting the request when ctx is cancelled
+	CaptureToStreamContext(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error)
+	// Same as CaptureHTMLToStream, aborting the request when ctx is cancelled
+	CaptureHTMLToStreamContext(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error)
+
+	// Capture a URL and stream the resulting pdf into w, returning the number of bytes written
+	CaptureToWriter(w io.Writer, url string, options ...HTMLToPDFCaptureOptions) (int64, error)
+	// Capture a HTML snippet and stream the resulting pdf into w, returning the number of bytes written
+	CaptureHTMLToWriter(w io.Writer, html string, options ...HTMLToPDFCaptureOptions) (int64, error)
+	// Capture a URL and atomically save the resulting pdf to path, returning the path written.
+	// If path is empty or a directory, the Filename option is used as the file name
+	CaptureToFile(path string, url string, options ...HTMLToPDFCaptureOptions) (string, error)
+	// Capture a HTML snippet and atomically save the resulting pdf to path, returning the path written.
+	// If path is empty or a directory, the Filename option is used as the file name
+	CaptureHTMLToFile(path string, html string, options ...HTMLToPDFCaptureOptions) (string, error)
+	// Same as CaptureToWriter, aborting the request when ctx is cancelled
+	CaptureToWriterContext(ctx context.Context, w io.Writer, url string, options ...HTMLToPDFCaptureOptions) (int64, error)
+	// Same as CaptureHTMLToWriter, aborting the request when ctx is cancelled
+	CaptureHTMLToWriterContext(ctx context.Context, w io.Writer, html string, options ...HTMLToPDFCaptureOptions) (int64, error)
+	// Same as CaptureToFile, aborting the request when ctx is cancelled
+	CaptureToFileContext(ctx context.Context, path string, url string, options ...HTMLToPDFCaptureOptions) (string, error)
+	// Same as CaptureHTMLToFile, aborting the request when ctx is cancelled
+	CaptureHTMLToFileContext(ctx context.Context, path string, html string, options ...HTMLToPDFCaptureOptions) (string, error)
+
+	// Capture many URLs or HTML snippets with bounded concurrency. Results are returned in request order,
+	// pairing each request with its result or error. If any capture fails, a *BatchError summarizing the failures is returned as well
+	CaptureBatch(ctx context.Context, requests []HTMLToPDFRequest, options BatchOptions) ([]HTMLToPDFBatchResult, error)
+
+	// Start capturing a URL in the background. Cancelling ctx or the future aborts the capture
+	CaptureAsync(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) *HTMLToPDFFuture
+	// Start capturing a HTML snippet in the background. Cancelling ctx or the future aborts the capture
+	CaptureHTMLAsync(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) *HTMLToPDFFuture
 }
 
 type htmlToPDFClient struct {
@@ -102,101 +176,170 @@ type htmlToPDFClient struct {
 }
 
 func (me *htmlToPDFClient) Capture(url string, options ...HTMLToPDFCaptureOptions) (HTMLToPDFCaptureResult, error) {
-	opt := htmlToPDFCallOptions{
-		URL:  url,
-		JSON: true,
-	}
+	return me.CaptureContext(context.Background(), url, options...)
+}
 
-	if len(options) > 0 {
-		opt.HTMLToPDFCaptureOptions = options[0]
-	}
+func (me *htmlToPDFClient) CaptureHTML(html string, options ...HTMLToPDFCaptureOptions) (HTMLToPDFCaptureResult, error) {
+	return me.CaptureHTMLContext(context.Background(), html, options...)
+}
 
-	var res struct {
-		HTMLToPDFCaptureResult
-		Error string `json:"error"`
-	}
-	httpres, _, err := me.do("POST", "/convert").JSON(opt).EndStruct(&res)
+func (me *htmlToPDFClient) CaptureToReader(url string, options ...HTMLToPDFCaptureOptions) (io.Reader, error) {
+	return me.CaptureToReaderContext(context.Background(), url, options...)
+}
 
-	if err != nil {
-		return HTMLToPDFCaptureResult{}, err
-	}
+func (me *htmlToPDFClient) CaptureHTMLToReader(html string, options ...HTMLToPDFCaptureOptions) (io.Reader, error) {
+	return me.CaptureHTMLToReaderContext(context.Background(), html, options...)
+}
 
-	if httpres.StatusCode > 300 {
-		return res.HTMLToPDFCaptureResult, errors.New(res.Error)
-	}
+func (me *htmlToPDFClient) CaptureContext(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) (HTMLToPDFCaptureResult, error) {
+	return me.capture(ctx, newHTMLToPDFCallOptions(url, "", true, options))
+}
 
-	return res.HTMLToPDFCaptureResult, err
+func (me *htmlToPDFClient) CaptureHTMLContext(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) (HTMLToPDFCaptureResult, error) {
+	return me.capture(ctx, newHTMLToPDFCallOptions("", html, true, options))
 }
 
-func (me *htmlToPDFClient) CaptureHTML(html string, options ...HTMLToPDFCaptureOptions) (HTMLToPDFCaptureResult, error) {
-	opt := htmlToPDFCallOptions{
-		HTML: html,
-		JSON: true,
-	}
+func (me *htmlToPDFClient) CaptureToReaderContext(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) (io.Reader, error) {
+	return me.captureReader(ctx, newHTMLToPDFCallOptions(url, "", false, options))
+}
 
-	if len(options) > 0 {
-		opt.HTMLToPDFCaptureOptions = options[0]
-	}
+func (me *htmlToPDFClient) CaptureHTMLToReaderContext(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) (io.Reader, error) {
+	return me.captureReader(ctx, newHTMLToPDFCallOptions("", html, false, options))
+}
 
-	var res struct {
-		HTMLToPDFCaptureResult
-		Error string `json:"error"`
-	}
-	httpres, _, err := me.do("POST", "/convert").JSON(opt).EndStruct(&res)
+func (me *htmlToPDFClient) CaptureToStream(url string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error) {
+	return me.CaptureToStreamContext(context.Background(), url, options...)
+}
 
-	if err != nil {
-		return HTMLToPDFCaptureResult{}, err
-	}
+func (me *htmlToPDFClient) CaptureHTMLToStream(html string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error) {
+	return me.CaptureHTMLToStreamContext(context.Background(), html, options...)
+}
 
-	if httpres.StatusCode > 300 {
-		return res.HTMLToPDFCaptureResult, errors.New(res.Error)
+func (me *htmlToPDFClient) CaptureToStreamContext(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error) {
+	return me.stream(ctx, "POST", "/convert", newHTMLToPDFCallOptions(url, "", false, options))
+}
+
+func (me *htmlToPDFClient) CaptureHTMLToStreamContext(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) (io.ReadCloser, error) {
+	return me.stream(ctx, "POST", "/convert", newHTMLToPDFCallOptions("", html, false, options))
+}
+
+func (me *htmlToPDFClient) CaptureToWriter(w io.Writer, url string, options ...HTMLToPDFCaptureOptions) (int64, error) {
+	return me.CaptureToWriterContext(context.Background(), w, url, options...)
+}
+
+func (me *htmlToPDFClient) CaptureHTMLToWriter(w io.Writer, html string, options ...HTMLToPDFCaptureOptions) (int64, error) {
+	return me.CaptureHTMLToWriterContext(context.Background(), w, html, options...)
+}
+
+func (me *htmlToPDFClient) CaptureToFile(path string, url string, options ...HTMLToPDFCaptureOptions) (string, error) {
+	return me.CaptureToFileContext(context.Background(), path, url, options...)
+}
+
+func (me *htmlToPDFClient) CaptureHTMLToFile(path string, html string, options ...HTMLToPDFCaptureOptions) (string, error) {
+	return me.CaptureHTMLToFileContext(context.Background(), path, html, options...)
+}
+
+func (me *htmlToPDFClient) CaptureToWriterContext(ctx context.Context, w io.Writer, url string, options ...HTMLToPDFCaptureOptions) (int64, error) {
+	stream, err := me.CaptureToStreamContext(ctx, url, options...)
+
+	return writeStream(w, stream, err)
+}
+
+func (me *htmlToPDFClient) CaptureHTMLToWriterContext(ctx context.Context, w io.Writer, html string, options ...HTMLToPDFCaptureOptions) (int64, error) {
+	stream, err := me.CaptureHTMLToStreamContext(ctx, html, options...)
+
+	return writeStream(w, stream, err)
+}
+
+func (me *htmlToPDFClient) CaptureToFileContext(ctx context.Context, path string, url string, options ...HTMLToPDFCaptureOptions) (string, error) {
+	opt := newHTMLToPDFCallOptions(url, "", false, options)
+
+	return writeStreamToFile(path, opt.Filename, func() (io.ReadCloser, error) {
+		return me.stream(ctx, "POST", "/convert", opt)
+	})
+}
+
+func (me *htmlToPDFClient) CaptureHTMLToFileContext(ctx context.Context, path string, html string, options ...HTMLToPDFCaptureOptions) (string, error) {
+	opt := newHTMLToPDFCallOptions("", html, false, options)
+
+	return writeStreamToFile(path, opt.Filename, func() (io.ReadCloser, error) {
+		return me.stream(ctx, "POST", "/convert", opt)
+	})
+}
+
+func (me *htmlToPDFClient) CaptureBatch(ctx context.Context, requests []HTMLToPDFRequest, options BatchOptions) ([]HTMLToPDFBatchResult, error) {
+	results := make([]HTMLToPDFBatchResult, len(requests))
+
+	errs, err := runBatch(ctx, len(requests), options, func(ctx context.Context, i int) error {
+		req := requests[i]
+		res, err := me.capture(ctx, newHTMLToPDFCallOptions(req.URL, req.HTML, true, []HTMLToPDFCaptureOptions{req.Options}))
+
+		results[i] = HTMLToPDFBatchResult{Request: req, Result: res, Err: err}
+
+		return err
+	})
+
+	// Requests skipped after ctx was cancelled only have their error set
+	for i := range results {
+		results[i].Request = requests[i]
+		results[i].Err = errs[i]
 	}
 
-	return res.HTMLToPDFCaptureResult, err
+	return results, err
 }
 
-func (me *htmlToPDFClient) CaptureToReader(url string, options ...HTMLToPDFCaptureOptions) (io.Reader, error) {
+func (me *htmlToPDFClient) CaptureAsync(ctx context.Context, url string, options ...HTMLToPDFCaptureOptions) *HTMLToPDFFuture {
+	return me.captureAsync(ctx, newHTMLToPDFCallOptions(url, "", true, options))
+}
+
+func (me *htmlToPDFClient) CaptureHTMLAsync(ctx context.Context, html string, options ...HTMLToPDFCaptureOptions) *HTMLToPDFFuture {
+	return me.captureAsync(ctx, newHTMLToPDFCallOptions("", html, true, options))
+}
+
+func newHTMLToPDFCallOptions(url string, html string, jsonResult bool, options []HTMLToPDFCaptureOptions) htmlToPDFCallOptions {
 	opt := htmlToPDFCallOptions{
 		URL:  url,
-		JSON: false,
+		HTML: html,
+		JSON: jsonResult,
 	}
 
 	if len(options) > 0 {
 		opt.HTMLToPDFCaptureOptions = options[0]
 	}
 
-	resp, body, err := me.do("POST", "/convert").JSON(opt).End()
+	return opt
+}
+
+func (me *htmlToPDFClient) capture(ctx context.Context, opt htmlToPDFCallOptions) (HTMLToPDFCaptureResult, error) {
+	var res HTMLToPDFCaptureResult
+	resp, err := me.doStruct(ctx, "POST", "/convert", opt, &res)
 
 	if err != nil {
-		return nil, err
+		return HTMLToPDFCaptureResult{}, err
 	}
 
-	if resp.StatusCode > 300 {
-		return nil, errors.New(resp.Status)
-	}
+	res.Attempts = resp.attempts
 
-	return bytes.NewReader(body), err
+	return res, nil
 }
 
-func (me *htmlToPDFClient) CaptureHTMLToReader(html string, options ...HTMLToPDFCaptureOptions) (io.Reader, error) {
-	opt := htmlToPDFCallOptions{
-		HTML: html,
-		JSON: false,
-	}
+func (me *htmlToPDFClient) captureAsync(ctx context.Context, opt htmlToPDFCallOptions) *HTMLToPDFFuture {
+	f := &HTMLToPDFFuture{}
+	f.future = startFuture(ctx, func(ctx context.Context) error {
+		var err error
+		f.result, err = me.capture(ctx, opt)
+		return err
+	})
 
-	if len(options) > 0 {
-		opt.HTMLToPDFCaptureOptions = options[0]
-	}
+	return f
+}
 
-	resp, body, err := me.do("POST", "/convert").JSON(opt).End()
+func (me *htmlToPDFClient) captureReader(ctx context.Context, opt htmlToPDFCallOptions) (io.Reader, error) {
+	resp, err := me.do(ctx, "POST", "/convert", opt)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode > 300 {
-		return nil, errors.New(resp.Status)
-	}
-
-	return bytes.NewReader(body), err
+	return bytes.NewReader(resp.data), nil
 }
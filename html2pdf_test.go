@@ -1,8 +1,13 @@
 package gorestpack
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 func Pdf(buf []byte) bool {
@@ -12,7 +17,7 @@ func Pdf(buf []byte) bool {
 }
 
 func Test_HTML2PDF_InvalidToken(t *testing.T) {
-	client := NewHTMLToPDFClient("INVALID_TOKEN")
+	client := newTestHTMLToPDFClient(t, "INVALID_TOKEN")
 	_, err := client.Capture("https://google.com/")
 
 	if err == nil {
@@ -22,12 +27,16 @@ func Test_HTML2PDF_InvalidToken(t *testing.T) {
 	if err.Error() != "The access token is invalid or you are not subscribed to any plan. Please visit the API console and choose your subscription plan." {
 		t.Errorf("Must return error with invalid token warning")
 	}
+
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Must match ErrInvalidToken")
+	}
 }
 
 func Test_HTML2PDF_Capture(t *testing.T) {
 	pdfToken := os.Getenv("PDF_TOKEN")
 
-	client := NewHTMLToPDFClient(pdfToken)
+	client := newTestHTMLToPDFClient(t, pdfToken)
 	resp, err := client.Capture("https://google.com/")
 
 	if err != nil {
@@ -43,7 +52,7 @@ func Test_HTML2PDF_Capture(t *testing.T) {
 func Test_HTML2PDF_Capture_404(t *testing.T) {
 	pdfToken := os.Getenv("PDF_TOKEN")
 
-	client := NewHTMLToPDFClient(pdfToken)
+	client := newTestHTMLToPDFClient(t, pdfToken)
 	_, err := client.Capture("https://google/")
 
 	if err == nil {
@@ -58,7 +67,7 @@ func Test_HTML2PDF_Capture_404(t *testing.T) {
 func Test_HTML2PDF_Capture_Reader(t *testing.T) {
 	pdfToken := os.Getenv("PDF_TOKEN")
 
-	client := NewHTMLToPDFClient(pdfToken)
+	client := newTestHTMLToPDFClient(t, pdfToken)
 	resp, err := client.CaptureToReader("https://google.com")
 
 	if err != nil {
@@ -80,14 +89,35 @@ func Test_HTML2PDF_Capture_Reader(t *testing.T) {
 func Test_HTML2PDF_Capture_Reader_404(t *testing.T) {
 	pdfToken := os.Getenv("PDF_TOKEN")
 
-	client := NewHTMLToPDFClient(pdfToken)
+	client := newTestHTMLToPDFClient(t, pdfToken)
 	_, err := client.CaptureToReader("https://google/")
 
 	if err == nil {
 		t.Errorf("Must return error")
 	}
 
-	if err.Error() != "400 Bad Request" {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
 		t.Errorf("Must return 400 Bad Request")
 	}
 }
+
+func Test_HTML2PDF_CaptureContext_Cancel(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CaptureContext(ctx, "https://google.com/")
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Must return context.DeadlineExceeded, get: %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+package gorestpack
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// States of a job item
+const (
+	JobPending = "pending"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Single capture of a job, identified by an ID unique within the job. Either URL or HTML must be set
+type JobItem struct {
+	ID   string `json:"id"`
+	URL  string `json:"url,omitempty"`
+	HTML string `json:"html,omitempty"`
+}
+
+// Captures a job item and returns the location of its output, e.g. a file path or cdn url
+type JobFunc func(ctx context.Context, item JobItem) (string, error)
+
+// Options controlling a job run. ItemTimeout bounds each attempt of an item.
+type JobOptions struct {
+	BatchOptions
+	// Maximum number of attempts per item, across runs. Failed items are retried within a run until it is reached. Defaults to 3.
+	MaxAttempts int
+	// Delays between the attempts of an item within a run. Only the backoff settings are used.
+	Backoff RetryPolicy
+}
+
+// State of a job item as recorded in the journal
+type JobItemState struct {
+	ID       string    `json:"id"`
+	State    string    `json:"state"`
+	Output   string    `json:"output,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Attempts int       `json:"attempts"`
+	Time     time.Time `json:"time"`
+}
+
+// Progress of a job
+type JobStatus struct {
+	ID      string
+	Total   int
+	Pending int
+	Done    int
+	Failed  int
+	// Latest state of each item, by item ID.
+	Items map[string]JobItemState
+}
+
+// Runs capture jobs recording the state of each item in an append-only journal, one file per job in dir.
+// Failed items are retried up to JobOptions.MaxAttempts. A job restarted with the same ID skips completed items
+// and retries failed ones that have attempts left.
+type JobRunner struct {
+	dir string
+}
+
+// Create a new JobRunner keeping its journals in dir
+func NewJobRunner(dir string) *JobRunner {
+	return &JobRunner{dir: dir}
+}
+
+// Capture the items of job id with fn, resuming a previous run of the same job.
+// Returns the job status after the run, and a *BatchError if items failed during this run.
+func (me *JobRunner) Run(ctx context.Context, id string, items []JobItem, fn JobFunc, options JobOptions) (JobStatus, error) {
+	status, err := me.Status(id)
+
+	if err != nil && !os.IsNotExist(err) {
+		return status, err
+	}
+
+	if err := os.MkdirAll(me.dir, 0755); err != nil {
+		return status, err
+	}
+
+	journal, err := os.OpenFile(me.path(id), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return status, err
+	}
+
+	defer journal.Close()
+
+	maxAttempts := options.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+
+	var mu sync.Mutex
+	var writeErr error
+
+	write := func(state JobItemState) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		state.Time = time.Now()
+		status.Items[state.ID] = state
+
+		line, _ := json.Marshal(state)
+		if _, err := journal.Write(append(line, '\n')); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+
+	var todo []JobItem
+
+	for _, item := range items {
+		state, known := status.Items[item.ID]
+
+		if !known {
+			state = JobItemState{ID: item.ID, State: JobPending}
+			write(state)
+		}
+
+		if state.State == JobDone || (state.State == JobFailed && state.Attempts >= maxAttempts) {
+			continue
+		}
+
+		todo = append(todo, item)
+	}
+
+	if err := journal.Sync(); err != nil {
+		return status, err
+	}
+
+	// ItemTimeout applies to each attempt rather than to all attempts of an item
+	batchOptions := options.BatchOptions
+	batchOptions.ItemTimeout = 0
+
+	_, err = runBatch(ctx, len(todo), batchOptions, func(ctx context.Context, i int) error {
+		item := todo[i]
+
+		mu.Lock()
+		attempts := status.Items[item.ID].Attempts
+		mu.Unlock()
+
+		for retry := 1; ; retry++ {
+			attempts++
+			output, err := runJobAttempt(ctx, item, fn, options.ItemTimeout)
+
+			// Items interrupted by cancelling the run stay pending for the next one
+			if err != nil && ctx.Err() != nil {
+				return err
+			}
+
+			state := JobItemState{ID: item.ID, State: JobDone, Output: output, Attempts: attempts}
+
+			if err != nil {
+				state.State = JobFailed
+				state.Error = err.Error()
+			}
+
+			write(state)
+
+			if err == nil || attempts >= maxAttempts {
+				return err
+			}
+
+			if sleepContext(ctx, options.Backoff.delay(retry, nil)) != nil {
+				return ctx.Err()
+			}
+		}
+	})
+
+	if syncErr := journal.Sync(); syncErr != nil && writeErr == nil {
+		writeErr = syncErr
+	}
+
+	status = summarize(id, status.Items)
+
+	if writeErr != nil {
+		return status, writeErr
+	}
+
+	return status, err
+}
+
+// Runs a single attempt of an item, bounded by timeout if set
+func runJobAttempt(ctx context.Context, item JobItem, fn JobFunc, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return fn(ctx, item)
+}
+
+// Job and item IDs are used as file names and must not contain path separators
+func validJobName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// Path of the output file of item id in dir
+func jobOutputPath(dir string, id string, ext string) (string, error) {
+	if !validJobName(id) {
+		return "", errors.New("gorestpack: invalid job item id " + id)
+	}
+
+	return filepath.Join(dir, id+ext), nil
+}
+
+// Status of job id, read from its journal
+func (me *JobRunner) Status(id string) (JobStatus, error) {
+	status := JobStatus{ID: id, Items: map[string]JobItemState{}}
+
+	if !validJobName(id) {
+		return status, errors.New("gorestpack: invalid job id " + id)
+	}
+
+	file, err := os.Open(me.path(id))
+
+	if err != nil {
+		return status, err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var state JobItemState
+
+		// A line cut short by a crash is ignored, the item is simply captured again
+		if json.Unmarshal(scanner.Bytes(), &state) != nil || state.ID == "" {
+			continue
+		}
+
+		status.Items[state.ID] = state
+	}
+
+	if err := scanner.Err(); err != nil {
+		return status, err
+	}
+
+	return summarize(id, status.Items), nil
+}
+
+func (me *JobRunner) path(id string) string {
+	return filepath.Join(me.dir, id+".journal")
+}
+
+func summarize(id string, items map[string]JobItemState) JobStatus {
+	status := JobStatus{ID: id, Total: len(items), Items: items}
+
+	for _, state := range items {
+		switch state.State {
+		case JobDone:
+			status.Done++
+		case JobFailed:
+			status.Failed++
+		default:
+			status.Pending++
+		}
+	}
+
+	return status
+}
+
+// JobFunc capturing screenshots with client. Outputs are saved as <id>.<format> in dir,
+// or, if dir is empty, left on the Restpack cdn and reported by url.
+func ScreenshotJobFunc(client ScreenshotClient, dir string, options ScreenshotCaptureOptions) JobFunc {
+	ext := ".png"
+	if options.Format != "" {
+		ext = "." + string(options.Format)
+	}
+
+	return func(ctx context.Context, item JobItem) (string, error) {
+		if dir != "" {
+			path, err := jobOutputPath(dir, item.ID, ext)
+
+			if err != nil {
+				return "", err
+			}
+
+			if item.HTML != "" {
+				return client.CaptureHTMLToFileContext(ctx, path, item.HTML, options)
+			}
+			return client.CaptureToFileContext(ctx, path, item.URL, options)
+		}
+
+		var res ScreenshotCaptureResult
+		var err error
+
+		if item.HTML != "" {
+			res, err = client.CaptureHTMLContext(ctx, item.HTML, options)
+		} else {
+			res, err = client.CaptureContext(ctx, item.URL, options)
+		}
+
+		return res.Image, err
+	}
+}
+
+// JobFunc converting documents with client. Outputs are saved as <id>.pdf in dir,
+// or, if dir is empty, left on the Restpack cdn and reported by url.
+func HTMLToPDFJobFunc(client HTMLToPDFClient, dir string, options HTMLToPDFCaptureOptions) JobFunc {
+	return func(ctx context.Context, item JobItem) (string, error) {
+		if dir != "" {
+			path, err := jobOutputPath(dir, item.ID, ".pdf")
+
+			if err != nil {
+				return "", err
+			}
+
+			if item.HTML != "" {
+				return client.CaptureHTMLToFileContext(ctx, path, item.HTML, options)
+			}
+			return client.CaptureToFileContext(ctx, path, item.URL, options)
+		}
+
+		var res HTMLToPDFCaptureResult
+		var err error
+
+		if item.HTML != "" {
+			res, err = client.CaptureHTMLContext(ctx, item.HTML, options)
+		} else {
+			res, err = client.CaptureContext(ctx, item.URL, options)
+		}
+
+		return res.Image, err
+	}
+}
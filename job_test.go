@@ -0,0 +1,115 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Job_Resume(t *testing.T) {
+	server := newTestServer(t)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL))
+
+	dir := t.TempDir()
+	runner := NewJobRunner(filepath.Join(dir, "journal"))
+	capture := ScreenshotJobFunc(client, dir, ScreenshotCaptureOptions{})
+
+	items := []JobItem{
+		{ID: "google", URL: "https://google.com/"},
+		{ID: "broken", URL: "https://google/"},
+		{ID: "snippet", HTML: "<h1>Test</h1>"},
+	}
+
+	// Simulate a process that dies after capturing the first item
+	calls := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted := func(ctx context.Context, item JobItem) (string, error) {
+		calls++
+		if calls > 1 {
+			cancel()
+			return "", ctx.Err()
+		}
+		return capture(ctx, item)
+	}
+
+	status, _ := runner.Run(ctx, "job-1", items, interrupted, JobOptions{BatchOptions: BatchOptions{Concurrency: 1}})
+
+	if status.Done != 1 || status.Pending != 2 {
+		t.Fatalf("Must record first item only, get: %+v", status)
+	}
+
+	retries := JobOptions{MaxAttempts: 3, Backoff: RetryPolicy{InitialBackoff: time.Millisecond}}
+	status, err := runner.Run(context.Background(), "job-1", items, capture, retries)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed != 1 {
+		t.Errorf("Must report failed item, get: %v", err)
+	}
+
+	if status.Done != 2 || status.Failed != 1 || status.Items["broken"].Attempts != 3 {
+		t.Errorf("Must resume remaining items and retry failed ones, get: %+v", status)
+	}
+
+	if _, err := os.Stat(status.Items["snippet"].Output); err != nil {
+		t.Errorf("Must record output location, get: %v", err)
+	}
+
+	if len(server.Requests()) != 5 {
+		t.Errorf("Must not capture completed items again, get: %d requests", len(server.Requests()))
+	}
+
+	runner.Run(context.Background(), "job-1", items, capture, retries)
+
+	status, err = runner.Status("job-1")
+
+	if err != nil || status.Items["broken"].Attempts != 3 || len(server.Requests()) != 5 {
+		t.Errorf("Must stop retrying after MaxAttempts, get: %+v, %d requests", status.Items["broken"], len(server.Requests()))
+	}
+}
+
+func Test_Job_RetryAfterTimeout(t *testing.T) {
+	runner := NewJobRunner(t.TempDir())
+
+	calls := 0
+	slowOnce := func(ctx context.Context, item JobItem) (string, error) {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "done", nil
+	}
+
+	status, err := runner.Run(context.Background(), "job-1", []JobItem{{ID: "slow", URL: "https://google.com/"}}, slowOnce, JobOptions{
+		BatchOptions: BatchOptions{ItemTimeout: 20 * time.Millisecond},
+		MaxAttempts:  3,
+		Backoff:      RetryPolicy{InitialBackoff: time.Millisecond},
+	})
+
+	if err != nil || calls != 2 || status.Items["slow"].State != JobDone || status.Items["slow"].Attempts != 2 {
+		t.Errorf("Must retry items after a timed out attempt, get: %+v, %d calls, %v", status.Items["slow"], calls, err)
+	}
+}
+
+func Test_Job_ItemPath(t *testing.T) {
+	server := newTestServer(t)
+	client := NewHTMLToPDFClient(testToken, WithBaseURL(server.URL))
+
+	dir := t.TempDir()
+	capture := HTMLToPDFJobFunc(client, filepath.Join(dir, "out"), HTMLToPDFCaptureOptions{})
+
+	if _, err := capture(context.Background(), JobItem{ID: "../escaped", URL: "https://google.com/"}); err == nil {
+		t.Errorf("Must reject item ids with path separators")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escaped.pdf")); !os.IsNotExist(err) {
+		t.Errorf("Must not write outside dir")
+	}
+
+	if len(server.Requests()) != 0 {
+		t.Errorf("Must not capture invalid items")
+	}
+}
@@ -0,0 +1,202 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Returned by a fail fast RateLimiter when a request can not be sent right away.
+var ErrLimitExceeded = errors.New("gorestpack: client side rate limit exceeded")
+
+// Throttles API requests sent by a client. A single Limiter may be shared by several clients.
+type Limiter interface {
+	// Wait until a request may be sent. The returned function must be called once the request completes.
+	Acquire(ctx context.Context) (release func(), err error)
+	// Inspect an API response, e.g. to adapt to rate limit headers.
+	Observe(resp *http.Response)
+}
+
+// Throttle API requests with limiter. Pass the same limiter to several clients to share the quota between them.
+func WithLimiter(limiter Limiter) Option {
+	return func(cfg *config) {
+		cfg.limiter = limiter
+	}
+}
+
+// Configuration of a RateLimiter, usually derived from plan quotas
+type RateLimiterConfig struct {
+	// Sustained number of requests per second. Zero means unlimited.
+	RequestsPerSecond float64
+	// Maximum number of requests sent in a burst. Defaults to 1.
+	Burst int
+	// Maximum number of requests in flight at the same time. Zero means unlimited.
+	MaxInFlight int
+	// Return ErrLimitExceeded instead of waiting when a request can not be sent right away.
+	FailFast bool
+}
+
+// Token bucket rate limiter with an optional cap on in-flight requests.
+// It pauses when the API reports an exhausted quota through X-RateLimit-Remaining / X-RateLimit-Reset or Retry-After headers.
+type RateLimiter struct {
+	config   RateLimiterConfig
+	inFlight chan struct{}
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// Create a new RateLimiter with supplied configuration
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	if config.Burst < 1 {
+		config.Burst = 1
+	}
+
+	limiter := &RateLimiter{
+		config: config,
+		tokens: float64(config.Burst),
+		last:   time.Now(),
+	}
+
+	if config.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, config.MaxInFlight)
+	}
+
+	return limiter
+}
+
+func (me *RateLimiter) Acquire(ctx context.Context) (func(), error) {
+	if err := me.acquireSlot(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := me.acquireToken(ctx); err != nil {
+		me.releaseSlot()
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() { once.Do(me.releaseSlot) }, nil
+}
+
+func (me *RateLimiter) Observe(resp *http.Response) {
+	var until time.Time
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if wait, ok := retryAfter(resp.Header); ok {
+			until = time.Now().Add(wait)
+		}
+	}
+
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil && remaining <= 0 {
+		if reset, ok := rateLimitReset(resp.Header.Get("X-RateLimit-Reset")); ok && reset.After(until) {
+			until = reset
+		}
+	}
+
+	if until.IsZero() {
+		return
+	}
+
+	me.mu.Lock()
+	if until.After(me.pausedUntil) {
+		me.pausedUntil = until
+	}
+	me.mu.Unlock()
+}
+
+func (me *RateLimiter) acquireSlot(ctx context.Context) error {
+	if me.inFlight == nil {
+		return nil
+	}
+
+	if me.config.FailFast {
+		select {
+		case me.inFlight <- struct{}{}:
+			return nil
+		default:
+			return ErrLimitExceeded
+		}
+	}
+
+	select {
+	case me.inFlight <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (me *RateLimiter) releaseSlot() {
+	if me.inFlight != nil {
+		<-me.inFlight
+	}
+}
+
+func (me *RateLimiter) acquireToken(ctx context.Context) error {
+	for {
+		wait := me.reserve()
+
+		if wait <= 0 {
+			return nil
+		}
+
+		if me.config.FailFast {
+			return ErrLimitExceeded
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Takes a token if one is available, otherwise returns the time to wait before trying again
+func (me *RateLimiter) reserve() time.Duration {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Before(me.pausedUntil) {
+		return me.pausedUntil.Sub(now)
+	}
+
+	if me.config.RequestsPerSecond <= 0 {
+		return 0
+	}
+
+	me.tokens += now.Sub(me.last).Seconds() * me.config.RequestsPerSecond
+	me.last = now
+
+	if me.tokens > float64(me.config.Burst) {
+		me.tokens = float64(me.config.Burst)
+	}
+
+	if me.tokens >= 1 {
+		me.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - me.tokens) / me.config.RequestsPerSecond * float64(time.Second))
+}
+
+// Parses X-RateLimit-Reset given either as a unix timestamp or as seconds until reset
+func rateLimitReset(value string) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+
+	if seconds > 1000000000 {
+		return time.Unix(seconds, 0), true
+	}
+
+	return time.Now().Add(time.Duration(seconds) * time.Second), true
+}
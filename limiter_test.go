@@ -0,0 +1,71 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_RateLimiter_FailFast(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{MaxInFlight: 1, FailFast: true})
+
+	release, err := limiter.Acquire(context.Background())
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if _, err := limiter.Acquire(context.Background()); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Must return ErrLimitExceeded, get: %v", err)
+	}
+
+	release()
+
+	if _, err := limiter.Acquire(context.Background()); err != nil {
+		t.Errorf("Must acquire after release, get: %v", err)
+	}
+}
+
+func Test_RateLimiter_TokenBucket(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{RequestsPerSecond: 20, Burst: 1})
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		release, err := limiter.Acquire(context.Background())
+
+		if err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+
+		release()
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Must throttle requests, took: %s", elapsed)
+	}
+}
+
+func Test_RateLimiter_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "60")
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png"}`))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimiterConfig{FailFast: true})
+	screenshot := NewScreenshotClient("TOKEN", WithBaseURL(server.URL), WithLimiter(limiter))
+	pdf := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL), WithLimiter(limiter))
+
+	if _, err := screenshot.Capture("https://google.com/"); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if _, err := pdf.Capture("https://google.com/"); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Shared limiter must pause after quota is exhausted, get: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package gorestpack
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Replacement logged for secret header values
+const redacted = "[REDACTED]"
+
+// Header names whose values are never logged
+var secretHeader = regexp.MustCompile(`(?i)(auth|token|secret|key|password|passwd|cookie|session|credential)`)
+
+// Header values that carry credentials regardless of the header name
+var secretValue = regexp.MustCompile(`(?i)^\s*(bearer|basic|digest|token)\s+\S`)
+
+// Log every API request to logger: endpoint, capture source, option summary, duration, status and cached flag.
+// The access token and secret looking values of the Headers option are redacted.
+func WithLogger(logger *slog.Logger) Option {
+	return func(cfg *config) {
+		cfg.logger = logger
+	}
+}
+
+// Log successful API requests at success and failed ones at failure. Defaults to slog.LevelDebug and slog.LevelWarn.
+func WithLogLevels(success slog.Level, failure slog.Level) Option {
+	return func(cfg *config) {
+		cfg.logSuccess = success
+		cfg.logFailure = failure
+	}
+}
+
+func logging(logger *slog.Logger, success slog.Level, failure slog.Level) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			summary := req.Summary()
+			start := time.Now()
+
+			attrs := []slog.Attr{
+				slog.String("service", req.Service),
+				slog.String("endpoint", req.Endpoint),
+				slog.String("source", summary.Source),
+			}
+
+			if summary.Source == SourceHTML {
+				attrs = append(attrs, slog.Int("html_size", summary.HTMLSize))
+			} else {
+				attrs = append(attrs, slog.String("url", summary.URL))
+			}
+
+			attrs = append(attrs, slog.Group("options",
+				slog.String("mode", summary.Mode),
+				slog.String("format", summary.Format),
+				slog.Bool("json", summary.JSON),
+				slog.String("headers", redactHeaderLines(summary.Headers)),
+			))
+			attrs = append(attrs, slog.Any("request_headers", redactHeader(req.Header)))
+
+			resp, err := next(ctx, req)
+
+			if err != nil {
+				attrs = append(attrs, slog.Duration("duration", time.Since(start)), slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, failure, "restpack request failed", attrs...)
+				return resp, err
+			}
+
+			ObserveResponse(req, resp, func(res ResponseSummary) {
+				attrs = append(attrs,
+					slog.Duration("duration", time.Since(start)),
+					slog.Int("status", res.StatusCode),
+					slog.Int64("bytes", res.Bytes),
+				)
+
+				if summary.JSON {
+					attrs = append(attrs, slog.Bool("cached", res.Cached))
+				}
+
+				if res.RemoteStatus != "" {
+					attrs = append(attrs, slog.String("remote_status", res.RemoteStatus))
+				}
+
+				if res.Err != nil {
+					attrs = append(attrs, slog.String("error", res.Err.Error()))
+					logger.LogAttrs(ctx, failure, "restpack request failed", attrs...)
+					return
+				}
+
+				logger.LogAttrs(ctx, success, "restpack request", attrs...)
+			})
+
+			return resp, nil
+		}
+	}
+}
+
+// Copies header as a flat map with secret values redacted
+func redactHeader(header http.Header) map[string]string {
+	res := make(map[string]string, len(header))
+
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+
+		if strings.EqualFold(name, "x-access-token") || secretHeader.MatchString(name) || secretValue.MatchString(value) {
+			value = redacted
+		}
+
+		res[name] = value
+	}
+
+	return res
+}
+
+// Redacts secret values of newline separated "Name: value" headers, as supplied in the Headers option
+func redactHeaderLines(headers string) string {
+	if headers == "" {
+		return ""
+	}
+
+	lines := strings.Split(headers, "\n")
+
+	for i, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+
+		if len(parts) == 2 && (secretHeader.MatchString(parts[0]) || secretValue.MatchString(parts[1])) {
+			lines[i] = parts[0] + ": " + redacted
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,63 @@
+package gorestpack
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Logging_Redaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png","cached":"true"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := NewScreenshotClient("SECRET_TOKEN", WithBaseURL(server.URL), WithLogger(logger))
+	_, err := client.Capture("https://google.com/", ScreenshotCaptureOptions{
+		Headers: "Authorization: Basic dXNlcjpwYXNz\nX-Custom: Bearer abcdef\nX-Locale: en",
+	})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	out := buf.String()
+
+	for _, secret := range []string{"SECRET_TOKEN", "dXNlcjpwYXNz", "abcdef"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Must redact %s, get: %s", secret, out)
+		}
+	}
+
+	for _, expected := range []string{"level=DEBUG", "endpoint=/capture", "url=https://google.com/", "X-Locale: en", "status=200", "cached=true"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Must log %s, get: %s", expected, out)
+		}
+	}
+}
+
+func Test_Logging_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL), WithLogger(logger), WithLogLevels(slog.LevelInfo, slog.LevelError))
+	client.CaptureHTMLToReader("<h1>Test</h1>")
+
+	out := buf.String()
+
+	if !strings.Contains(out, "level=ERROR") || !strings.Contains(out, "html_size=13") {
+		t.Errorf("Must log failure at configured level, get: %s", out)
+	}
+}
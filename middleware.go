@@ -0,0 +1,71 @@
+package gorestpack
+
+import (
+	"context"
+	"net/http"
+)
+
+// Names of the Restpack services, as reported in Request.Service
+const (
+	ServiceScreenshot = "screenshot"
+	ServiceHTMLToPDF  = "html2pdf"
+)
+
+// API request passed through the middleware chain. Middleware may modify it before calling the next handler.
+type Request struct {
+	// Restpack service being called, ServiceScreenshot or ServiceHTMLToPDF.
+	Service string
+	// HTTP method of the API request.
+	Method string
+	// API endpoint, /capture or /convert.
+	Endpoint string
+	// Full URL of the API request.
+	URL string
+	// JSON serialized capture options sent as the request body.
+	Options []byte
+	// Headers sent with the request, including the access token.
+	Header http.Header
+}
+
+// Sends an API request and returns the response. The response body is left unread.
+type Handler func(ctx context.Context, req *Request) (*http.Response, error)
+
+// Wraps a Handler, e.g. for logging, metrics, header injection or policy checks.
+type Middleware func(next Handler) Handler
+
+// Run every API request through middleware. The first middleware supplied is the outermost one.
+// Middleware applies to each attempt, so retried requests pass through it again.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(cfg *config) {
+		cfg.middleware = append(cfg.middleware, middleware...)
+	}
+}
+
+// Outcome of a capture call
+type CallResult struct {
+	// Number of API requests made, zero when the result was served from the local Cache.
+	Attempts int
+	// Error of the call, nil on success.
+	Err error
+}
+
+// Observes each capture call as a whole, including its retries, hedged attempts and results served from the local Cache.
+// It is called before the call with the request to be sent, without headers, and returns the context the attempts of
+// the call run with, along with a function called once the call is over. For streamed captures that is when the body is closed.
+type CallHook func(ctx context.Context, req *Request) (context.Context, func(CallResult))
+
+// Run every capture call through hooks. The first hook supplied is the outermost one.
+// Unlike middleware, hooks apply once per call no matter how many attempts it takes.
+func WithCallHook(hooks ...CallHook) Option {
+	return func(cfg *config) {
+		cfg.callHooks = append(cfg.callHooks, hooks...)
+	}
+}
+
+func chain(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
@@ -0,0 +1,86 @@
+package gorestpack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Middleware_Chain(t *testing.T) {
+	var header string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Request-Id")
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	var seen Request
+	var status int
+
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) (*http.Response, error) {
+				order = append(order, name)
+				return next(ctx, req)
+			}
+		}
+	}
+
+	inject := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			req.Header.Set("X-Request-Id", "42")
+			resp, err := next(ctx, req)
+			seen = *req
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			return resp, err
+		}
+	}
+
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL), WithMiddleware(trace("outer"), trace("inner"), inject))
+
+	if _, err := client.Capture("https://google.com/", ScreenshotCaptureOptions{Format: "png"}); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Must run middleware in order, get: %v", order)
+	}
+
+	if header != "42" {
+		t.Errorf("Must send injected header, get: %s", header)
+	}
+
+	var opt screenshotCallOptions
+	json.Unmarshal(seen.Options, &opt)
+
+	if seen.Service != ServiceScreenshot || seen.Endpoint != "/capture" || opt.URL != "https://google.com/" || opt.Format != "png" {
+		t.Errorf("Must expose request details, get: %+v", seen)
+	}
+
+	if status != http.StatusOK {
+		t.Errorf("Must expose response, get: %d", status)
+	}
+}
+
+func Test_Middleware_Reject(t *testing.T) {
+	denied := errors.New("denied")
+
+	policy := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			return nil, denied
+		}
+	}
+
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL("http://127.0.0.1:0"), WithMiddleware(policy))
+
+	if _, err := client.CaptureToReader("https://google.com/"); !errors.Is(err, denied) {
+		t.Errorf("Must return middleware error, get: %v", err)
+	}
+}
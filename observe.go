@@ -0,0 +1,150 @@
+package gorestpack
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Kinds of capture sources, as reported in RequestSummary.Source
+const (
+	SourceURL  = "url"
+	SourceHTML = "html"
+)
+
+// Capture details decoded from a Request, for use in logging, metrics and tracing middleware
+type RequestSummary struct {
+	// SourceURL or SourceHTML.
+	Source string
+	// URL being captured, empty for HTML captures.
+	URL string
+	// Size in bytes of the HTML snippet being captured.
+	HTMLSize int
+	// Capturing mode, screenshot only.
+	Mode string
+	// Image output format, screenshot only.
+	Format string
+	// Whether a JSON result is requested instead of the binary output.
+	JSON bool
+	// Additional headers sent to the remote server, seperated with newline.
+	Headers string
+}
+
+// Decode the capture options carried by the request
+func (r *Request) Summary() RequestSummary {
+	var opt struct {
+		URL     string `json:"url"`
+		HTML    string `json:"html"`
+		Mode    string `json:"mode"`
+		Format  string `json:"format"`
+		JSON    bool   `json:"json"`
+		Headers string `json:"headers"`
+	}
+
+	json.Unmarshal(r.Options, &opt)
+
+	summary := RequestSummary{
+		Source:   SourceURL,
+		URL:      opt.URL,
+		HTMLSize: len(opt.HTML),
+		Mode:     opt.Mode,
+		Format:   opt.Format,
+		JSON:     opt.JSON,
+		Headers:  opt.Headers,
+	}
+
+	if opt.URL == "" && opt.HTML != "" {
+		summary.Source = SourceHTML
+	}
+
+	return summary
+}
+
+// Outcome of an API response, reported by ObserveResponse once its body is closed
+type ResponseSummary struct {
+	// HTTP status code of the API response.
+	StatusCode int
+	// Number of response body bytes read.
+	Bytes int64
+	// Whether the API served the result from its cache. Only known for JSON results.
+	Cached bool
+	// Status code returned by the remote server of the captured page. Only known for JSON results.
+	RemoteStatus string
+	// *APIError for unsuccessful responses, or the error encountered while reading the body.
+	Err error
+}
+
+// Largest JSON body buffered by ObserveResponse for decoding
+const maxObservedJSON = 1 << 20
+
+// Wrap resp.Body so that done is called once it is closed, with a summary of the response.
+// JSON bodies are decoded to report the cached flag, remote status and API errors.
+func ObserveResponse(req *Request, resp *http.Response, done func(ResponseSummary)) {
+	resp.Body = &observedBody{
+		ReadCloser: resp.Body,
+		req:        req,
+		resp:       resp,
+		buffer:     resp.StatusCode > 300 || strings.Contains(resp.Header.Get("Content-Type"), "json"),
+		done:       done,
+	}
+}
+
+type observedBody struct {
+	io.ReadCloser
+	req    *Request
+	resp   *http.Response
+	buffer bool
+	done   func(ResponseSummary)
+
+	bytes   int64
+	data    []byte
+	readErr error
+	once    sync.Once
+}
+
+func (me *observedBody) Read(p []byte) (int, error) {
+	n, err := me.ReadCloser.Read(p)
+	me.bytes += int64(n)
+
+	if me.buffer && len(me.data)+n <= maxObservedJSON {
+		me.data = append(me.data, p[:n]...)
+	}
+
+	if err != nil && err != io.EOF {
+		me.readErr = err
+	}
+
+	return n, err
+}
+
+func (me *observedBody) Close() error {
+	err := me.ReadCloser.Close()
+	me.once.Do(me.report)
+	return err
+}
+
+func (me *observedBody) report() {
+	summary := ResponseSummary{
+		StatusCode: me.resp.StatusCode,
+		Bytes:      me.bytes,
+		Err:        me.readErr,
+	}
+
+	if me.resp.StatusCode > 300 {
+		summary.Err = newAPIError(me.resp, me.data, me.req.Endpoint)
+	} else if me.buffer {
+		var res struct {
+			Cached       interface{} `json:"cached"`
+			RemoteStatus json.Number `json:"remote_status"`
+		}
+
+		if json.Unmarshal(me.data, &res) == nil {
+			summary.Cached = res.Cached == true || res.Cached == "true"
+			summary.RemoteStatus = res.RemoteStatus.String()
+		}
+	}
+
+	me.done(summary)
+}
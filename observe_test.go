@@ -0,0 +1,43 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Observe_Response(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"Too many requests"}`))
+	}))
+	defer server.Close()
+
+	var summary RequestSummary
+	var result ResponseSummary
+
+	observe := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			summary = req.Summary()
+			resp, err := next(ctx, req)
+			if err == nil {
+				ObserveResponse(req, resp, func(res ResponseSummary) { result = res })
+			}
+			return resp, err
+		}
+	}
+
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL), WithMiddleware(observe))
+	client.CaptureHTML("<h1>Test</h1>")
+
+	if summary.Source != SourceHTML || summary.HTMLSize != 13 || !summary.JSON {
+		t.Errorf("Must summarize request, get: %+v", summary)
+	}
+
+	if result.StatusCode != 429 || result.Bytes == 0 || !errors.Is(result.Err, ErrRateLimited) {
+		t.Errorf("Must summarize response, get: %+v", result)
+	}
+}
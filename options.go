@@ -0,0 +1,117 @@
+package gorestpack
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const defaultBaseURL = "https://restpack.io"
+
+// Option configures a client created with NewScreenshotClient or NewHTMLToPDFClient
+type Option func(*config)
+
+type config struct {
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+	baseURL    string
+	userAgent  string
+
+	retryPolicy RetryPolicy
+	limiter     Limiter
+	middleware  []Middleware
+	callHooks   []CallHook
+	logger      *slog.Logger
+	logSuccess  slog.Level
+	logFailure  slog.Level
+
+	tokenProvider TokenProvider
+
+	cache    Cache
+	cacheTTL time.Duration
+	dedup    *Deduplicator
+	breaker  *CircuitBreaker
+
+	hedgePolicy *HedgePolicy
+
+	skipValidation bool
+}
+
+func newConfig(options []Option) *config {
+	cfg := &config{
+		baseURL:    defaultBaseURL,
+		logSuccess: slog.LevelDebug,
+		logFailure: slog.LevelWarn,
+	}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return cfg
+}
+
+// Builds the http.Client shared by the services of a Client
+func (me *config) newHTTPClient() *http.Client {
+	httpClient := &http.Client{}
+
+	if me.httpClient != nil {
+		copied := *me.httpClient
+		httpClient = &copied
+	}
+
+	if me.transport != nil {
+		httpClient.Transport = me.transport
+	}
+
+	if me.timeout > 0 {
+		httpClient.Timeout = me.timeout
+	}
+
+	return httpClient
+}
+
+// Use the supplied http.Client for API requests. The client is copied, so WithTimeout and WithTransport do not modify it.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(cfg *config) {
+		cfg.httpClient = httpClient
+	}
+}
+
+// Use the supplied http.RoundTripper for API requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(cfg *config) {
+		cfg.transport = transport
+	}
+}
+
+// Limit the total time spent on a single API request, including reading the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.timeout = timeout
+	}
+}
+
+// Apply several options at once, e.g. to bundle middleware with a matching CallHook.
+func WithOptions(options ...Option) Option {
+	return func(cfg *config) {
+		for _, option := range options {
+			option(cfg)
+		}
+	}
+}
+
+// Send API requests to baseURL instead of https://restpack.io. The service path (e.g. /api/screenshot/v5) is appended to it.
+func WithBaseURL(baseURL string) Option {
+	return func(cfg *config) {
+		cfg.baseURL = baseURL
+	}
+}
+
+// Send a custom user-agent header with API requests.
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *config) {
+		cfg.userAgent = userAgent
+	}
+}
@@ -0,0 +1,60 @@
+package gorestpack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Options_BaseURL_UserAgent(t *testing.T) {
+	var path, userAgent, token string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		userAgent = r.Header.Get("User-Agent")
+		token = r.Header.Get("x-access-token")
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png"}`))
+	}))
+	defer server.Close()
+
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL+"/"), WithUserAgent("gorestpack-test"))
+	resp, err := client.Capture("https://google.com/")
+
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if resp.Image != "https://cdn.restpack.io/a.png" {
+		t.Errorf("Must return image url, get: %s", resp.Image)
+	}
+
+	if path != "/api/screenshot/v5/capture" {
+		t.Errorf("Must request /api/screenshot/v5/capture, get: %s", path)
+	}
+
+	if userAgent != "gorestpack-test" {
+		t.Errorf("Must send custom user agent, get: %s", userAgent)
+	}
+
+	if token != "TOKEN" {
+		t.Errorf("Must send access token, get: %s", token)
+	}
+}
+
+func Test_Options_HTTPClient_NotModified(t *testing.T) {
+	httpClient := &http.Client{}
+	c := New("TOKEN", WithHTTPClient(httpClient), WithTimeout(time.Second)).htmlToPDF.client
+
+	if httpClient.Timeout != 0 {
+		t.Errorf("Must not modify supplied http client")
+	}
+
+	if c.httpClient.Timeout != time.Second {
+		t.Errorf("Must apply timeout, get: %s", c.httpClient.Timeout)
+	}
+
+	if c.basePath != "https://restpack.io/api/html2pdf/v5" {
+		t.Errorf("Must default to restpack.io, get: %s", c.basePath)
+	}
+}
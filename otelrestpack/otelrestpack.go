@@ -0,0 +1,149 @@
+// Package otelrestpack traces gorestpack API requests with OpenTelemetry.
+//
+// Tracing is opt-in: clients only depend on OpenTelemetry when created with WithTracerProvider.
+package otelrestpack
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/restpackio/gorestpack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/restpackio/gorestpack/otelrestpack"
+
+// Configures the tracing middleware
+type Option func(*config)
+
+type config struct {
+	propagator propagation.TextMapPropagator
+}
+
+// Inject trace context into API requests with propagator instead of W3C trace context
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(cfg *config) {
+		cfg.propagator = propagator
+	}
+}
+
+// Trace every capture call of a client with spans from tp: one span per call, with a child span for each API request
+// it makes, including retries and hedged requests. Calls answered by the local Cache get a span without children.
+// W3C trace context is propagated unless another propagator is given with WithPropagator.
+func WithTracerProvider(tp trace.TracerProvider, options ...Option) gorestpack.Option {
+	return gorestpack.WithOptions(
+		gorestpack.WithCallHook(CallHook(tp)),
+		gorestpack.WithMiddleware(Middleware(tp, options...)),
+	)
+}
+
+// Create a gorestpack.CallHook producing a span for each capture call.
+// The span ends once the call is over, or for streamed captures once the body is closed.
+func CallHook(tp trace.TracerProvider) gorestpack.CallHook {
+	tracer := tp.Tracer(instrumentationName)
+
+	return func(ctx context.Context, req *gorestpack.Request) (context.Context, func(gorestpack.CallResult)) {
+		summary := req.Summary()
+
+		ctx, span := tracer.Start(ctx, req.Service+" "+req.Endpoint,
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(requestAttributes(req, summary)...),
+		)
+
+		return ctx, func(res gorestpack.CallResult) {
+			span.SetAttributes(attribute.Int("restpack.attempts", res.Attempts))
+
+			if res.Err != nil {
+				span.RecordError(res.Err)
+				span.SetStatus(codes.Error, res.Err.Error())
+			}
+
+			span.End()
+		}
+	}
+}
+
+// Create a gorestpack.Middleware producing a client span for each API request.
+// The span ends once the response body is closed, so it covers streamed downloads as well.
+func Middleware(tp trace.TracerProvider, options ...Option) gorestpack.Middleware {
+	tracer := tp.Tracer(instrumentationName)
+	cfg := &config{propagator: propagation.TraceContext{}}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return func(next gorestpack.Handler) gorestpack.Handler {
+		return func(ctx context.Context, req *gorestpack.Request) (*http.Response, error) {
+			summary := req.Summary()
+
+			ctx, span := tracer.Start(ctx, req.Method+" "+req.Service+" "+req.Endpoint,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(requestAttributes(req, summary)...),
+			)
+
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next(ctx, req)
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.End()
+				return resp, err
+			}
+
+			gorestpack.ObserveResponse(req, resp, func(res gorestpack.ResponseSummary) {
+				span.SetAttributes(
+					attribute.Int("http.response.status_code", res.StatusCode),
+					attribute.Int64("restpack.response.size", res.Bytes),
+				)
+
+				if summary.JSON {
+					span.SetAttributes(attribute.Bool("restpack.cached", res.Cached))
+				}
+
+				if res.RemoteStatus != "" {
+					span.SetAttributes(attribute.String("restpack.remote_status", res.RemoteStatus))
+				}
+
+				if res.Err != nil {
+					span.RecordError(res.Err)
+					span.SetStatus(codes.Error, res.Err.Error())
+				}
+
+				span.End()
+			})
+
+			return resp, nil
+		}
+	}
+}
+
+// Attributes describing the capture requested by req
+func requestAttributes(req *gorestpack.Request, summary gorestpack.RequestSummary) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("restpack.service", req.Service),
+		attribute.String("restpack.endpoint", req.Endpoint),
+		attribute.String("restpack.source", summary.Source),
+		attribute.Int("restpack.request.size", len(req.Options)),
+	}
+
+	if target, err := url.Parse(summary.URL); err == nil && target.Host != "" {
+		attrs = append(attrs, attribute.String("restpack.target.host", target.Host))
+	}
+
+	if summary.Mode != "" {
+		attrs = append(attrs, attribute.String("restpack.mode", summary.Mode))
+	}
+
+	if summary.Format != "" {
+		attrs = append(attrs, attribute.String("restpack.format", summary.Format))
+	}
+
+	return attrs
+}
@@ -0,0 +1,168 @@
+package otelrestpack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/restpackio/gorestpack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_Tracing_Capture(t *testing.T) {
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png","cached":"true","remote_status":"200"}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := gorestpack.NewScreenshotClient("TOKEN", gorestpack.WithBaseURL(server.URL), WithTracerProvider(tp))
+
+	if _, err := client.Capture("https://google.com/", gorestpack.ScreenshotCaptureOptions{Mode: "fullpage"}); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	spans := recorder.Ended()
+
+	if len(spans) != 2 {
+		t.Fatalf("Must record a call span and a request span, get: %d", len(spans))
+	}
+
+	request, call := spans[0], spans[1]
+
+	if request.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Errorf("Must record the request span as a child of the call span")
+	}
+
+	if traceparent == "" {
+		t.Errorf("Must propagate trace context")
+	}
+
+	attrs := spanAttributes(call)
+
+	if attrs["restpack.service"].AsString() != "screenshot" ||
+		attrs["restpack.source"].AsString() != "url" ||
+		attrs["restpack.target.host"].AsString() != "google.com" ||
+		attrs["restpack.mode"].AsString() != "fullpage" ||
+		attrs["restpack.attempts"].AsInt64() != 1 {
+		t.Errorf("Must set capture attributes, get: %v", call.Attributes())
+	}
+
+	attrs = spanAttributes(request)
+
+	if !attrs["restpack.cached"].AsBool() ||
+		attrs["restpack.remote_status"].AsString() != "200" {
+		t.Errorf("Must set response attributes, get: %v", request.Attributes())
+	}
+}
+
+func Test_Tracing_Retry(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png"}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := gorestpack.NewScreenshotClient("TOKEN",
+		gorestpack.WithBaseURL(server.URL),
+		gorestpack.WithRetryPolicy(gorestpack.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		gorestpack.WithCache(gorestpack.NewMemoryCache(1<<20), 0),
+		WithTracerProvider(tp),
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Capture("https://google.com/"); err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+	}
+
+	spans := recorder.Ended()
+
+	if len(spans) != 4 {
+		t.Fatalf("Must record two request spans and two call spans, get: %d", len(spans))
+	}
+
+	call := spans[2]
+
+	for _, request := range spans[:2] {
+		if request.Parent().SpanID() != call.SpanContext().SpanID() {
+			t.Errorf("Must record retried requests as children of the call span")
+		}
+	}
+
+	if spanAttributes(call)["restpack.attempts"].AsInt64() != 2 {
+		t.Errorf("Must report 2 attempts, get: %v", call.Attributes())
+	}
+
+	if cached := spans[3]; spanAttributes(cached)["restpack.attempts"].AsInt64() != 0 || cached.Parent().IsValid() {
+		t.Errorf("Must record a call span for a cached result, get: %v", cached.Attributes())
+	}
+}
+
+func Test_Tracing_Propagator(t *testing.T) {
+	var header http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png"}`))
+	}))
+	defer server.Close()
+
+	tp := sdktrace.NewTracerProvider()
+	client := gorestpack.NewScreenshotClient("TOKEN", gorestpack.WithBaseURL(server.URL), WithTracerProvider(tp, WithPropagator(headerPropagator{})))
+
+	if _, err := client.Capture("https://google.com/"); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if header.Get("traceparent") != "" || header.Get("x-test-trace") != "1" {
+		t.Errorf("Must propagate with the given propagator only")
+	}
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+// Propagator setting a fixed header
+type headerPropagator struct{}
+
+func (headerPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	carrier.Set("x-test-trace", "1")
+}
+
+func (headerPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return ctx
+}
+
+func (headerPropagator) Fields() []string {
+	return []string{"x-test-trace"}
+}
@@ -0,0 +1,165 @@
+// Package promrestpack exposes Prometheus metrics for gorestpack API usage.
+package promrestpack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/restpackio/gorestpack"
+)
+
+// Error classes reported in the class label of restpack_errors_total
+const (
+	ClassInvalidToken     = "invalid_token"
+	ClassRateLimited      = "rate_limited"
+	ClassRemoteNavigation = "remote_navigation"
+	ClassServer           = "server"
+	ClassAPI              = "api"
+	ClassLimited          = "limited"
+	ClassCircuitOpen      = "circuit_open"
+	ClassCanceled         = "canceled"
+	ClassTransport        = "transport"
+)
+
+// prometheus.Collector recording Restpack API usage, labelled by service and endpoint.
+// Register it with a prometheus.Registerer and pass Option() to the clients to be measured.
+type Collector struct {
+	requests  *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	bytes     *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	cacheHits *prometheus.CounterVec
+	inFlight  *prometheus.GaugeVec
+}
+
+// Create a new Collector
+func NewCollector() *Collector {
+	labels := []string{"service", "endpoint"}
+
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "restpack",
+			Name:      "requests_total",
+			Help:      "Number of Restpack API requests by response status code.",
+		}, append(labels, "code")),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "restpack",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of Restpack API requests, including reading the response body.",
+			Buckets:   []float64{.25, .5, 1, 2, 4, 8, 16, 32, 64},
+		}, labels),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "restpack",
+			Name:      "response_bytes_total",
+			Help:      "Number of response body bytes read from the Restpack API.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "restpack",
+			Name:      "errors_total",
+			Help:      "Number of failed Restpack API requests by error class.",
+		}, append(labels, "class")),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "restpack",
+			Name:      "cache_hits_total",
+			Help:      "Number of Restpack API results served from the Restpack cache.",
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "restpack",
+			Name:      "in_flight_requests",
+			Help:      "Number of Restpack API requests currently in flight.",
+		}, labels),
+	}
+}
+
+func (me *Collector) Describe(ch chan<- *prometheus.Desc) {
+	me.requests.Describe(ch)
+	me.duration.Describe(ch)
+	me.bytes.Describe(ch)
+	me.errors.Describe(ch)
+	me.cacheHits.Describe(ch)
+	me.inFlight.Describe(ch)
+}
+
+func (me *Collector) Collect(ch chan<- prometheus.Metric) {
+	me.requests.Collect(ch)
+	me.duration.Collect(ch)
+	me.bytes.Collect(ch)
+	me.errors.Collect(ch)
+	me.cacheHits.Collect(ch)
+	me.inFlight.Collect(ch)
+}
+
+// Client option recording the client's API requests with this collector
+func (me *Collector) Option() gorestpack.Option {
+	return gorestpack.WithMiddleware(me.Middleware())
+}
+
+// Middleware recording each API request with this collector. A request is complete once its response body is closed.
+func (me *Collector) Middleware() gorestpack.Middleware {
+	return func(next gorestpack.Handler) gorestpack.Handler {
+		return func(ctx context.Context, req *gorestpack.Request) (*http.Response, error) {
+			labels := prometheus.Labels{"service": req.Service, "endpoint": req.Endpoint}
+			inFlight := me.inFlight.With(labels)
+			start := time.Now()
+
+			inFlight.Inc()
+
+			resp, err := next(ctx, req)
+
+			if err != nil {
+				inFlight.Dec()
+				me.duration.With(labels).Observe(time.Since(start).Seconds())
+				me.errors.WithLabelValues(req.Service, req.Endpoint, Class(err)).Inc()
+				return resp, err
+			}
+
+			gorestpack.ObserveResponse(req, resp, func(res gorestpack.ResponseSummary) {
+				inFlight.Dec()
+				me.duration.With(labels).Observe(time.Since(start).Seconds())
+				me.requests.WithLabelValues(req.Service, req.Endpoint, strconv.Itoa(res.StatusCode)).Inc()
+				me.bytes.With(labels).Add(float64(res.Bytes))
+
+				if res.Cached {
+					me.cacheHits.With(labels).Inc()
+				}
+
+				if res.Err != nil {
+					me.errors.WithLabelValues(req.Service, req.Endpoint, Class(res.Err)).Inc()
+				}
+			})
+
+			return resp, nil
+		}
+	}
+}
+
+// Classify err into one of the Class constants
+func Class(err error) string {
+	switch {
+	case errors.Is(err, gorestpack.ErrInvalidToken):
+		return ClassInvalidToken
+	case errors.Is(err, gorestpack.ErrRateLimited):
+		return ClassRateLimited
+	case errors.Is(err, gorestpack.ErrRemoteNavigation):
+		return ClassRemoteNavigation
+	case errors.Is(err, gorestpack.ErrServer):
+		return ClassServer
+	case errors.Is(err, gorestpack.ErrLimitExceeded):
+		return ClassLimited
+	case errors.Is(err, gorestpack.ErrCircuitOpen):
+		return ClassCircuitOpen
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return ClassCanceled
+	}
+
+	var apiErr *gorestpack.APIError
+	if errors.As(err, &apiErr) {
+		return ClassAPI
+	}
+
+	return ClassTransport
+}
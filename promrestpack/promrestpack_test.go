@@ -0,0 +1,61 @@
+package promrestpack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/restpackio/gorestpack"
+)
+
+func Test_Collector(t *testing.T) {
+	fail := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if fail {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"Too many requests"}`))
+			return
+		}
+
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.pdf","cached":"true"}`))
+	}))
+	defer server.Close()
+
+	collector := NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	client := gorestpack.NewHTMLToPDFClient("TOKEN", gorestpack.WithBaseURL(server.URL), collector.Option())
+
+	if _, err := client.Capture("https://google.com/"); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	fail = true
+	client.Capture("https://google.com/")
+
+	if v := testutil.ToFloat64(collector.requests.WithLabelValues("html2pdf", "/convert", "200")); v != 1 {
+		t.Errorf("Must count successful requests, get: %v", v)
+	}
+
+	if v := testutil.ToFloat64(collector.cacheHits.WithLabelValues("html2pdf", "/convert")); v != 1 {
+		t.Errorf("Must count cache hits, get: %v", v)
+	}
+
+	if v := testutil.ToFloat64(collector.errors.WithLabelValues("html2pdf", "/convert", ClassRateLimited)); v != 1 {
+		t.Errorf("Must count errors by class, get: %v", v)
+	}
+
+	if v := testutil.ToFloat64(collector.inFlight.WithLabelValues("html2pdf", "/convert")); v != 0 {
+		t.Errorf("Must not leave requests in flight, get: %v", v)
+	}
+
+	if n, err := testutil.GatherAndCount(registry); err != nil || n == 0 {
+		t.Errorf("Must gather metrics, get: %d, %v", n, err)
+	}
+}
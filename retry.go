@@ -0,0 +1,153 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Controls how failed API requests are retried. The zero value disables retries.
+type RetryPolicy struct {
+	// Maximum number of attempts including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// Delay before the first retry. Defaults to 500ms.
+	InitialBackoff time.Duration
+	// Upper bound for the delay between attempts, including delays requested with Retry-After. Defaults to 10s.
+	MaxBackoff time.Duration
+	// Factor the delay is multiplied with after each attempt. Defaults to 2.
+	Multiplier float64
+	// Fraction of the delay to be randomized, between 0 and 1.
+	Jitter float64
+	// Decides whether an error is worth retrying. Defaults to IsRetryable.
+	Retryable func(err error) bool
+}
+
+// Retry failed API requests according to policy. A Retry-After header sent by the API takes precedence over the computed backoff,
+// up to MaxBackoff.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(cfg *config) {
+		cfg.retryPolicy = policy
+	}
+}
+
+// Reports whether err is a transient failure: rate limiting, a server error or a broken connection.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(apiErr, ErrRateLimited) || errors.Is(apiErr, ErrServer)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+func (me RetryPolicy) retryable(err error) bool {
+	if me.Retryable != nil {
+		return me.Retryable(err)
+	}
+
+	return IsRetryable(err)
+}
+
+type attemptReporterKey struct{}
+
+// Report the number of attempts made by each API request sent with the returned context, including retries.
+// Useful with methods whose result can not carry it, such as CaptureToImage or CaptureToStream.
+func ContextWithAttemptReporter(ctx context.Context, report func(attempts int)) context.Context {
+	return context.WithValue(ctx, attemptReporterKey{}, report)
+}
+
+// Delay before the attempt following the given one
+func (me RetryPolicy) delay(attempt int, header http.Header) time.Duration {
+	maxBackoff := me.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+
+	if wait, ok := retryAfter(header); ok {
+		if wait > maxBackoff {
+			return maxBackoff
+		}
+		return wait
+	}
+
+	backoff := float64(me.InitialBackoff)
+	if backoff <= 0 {
+		backoff = float64(500 * time.Millisecond)
+	}
+
+	multiplier := me.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	for i := 1; i < attempt && backoff < float64(maxBackoff); i++ {
+		backoff *= multiplier
+	}
+
+	if backoff > float64(maxBackoff) {
+		backoff = float64(maxBackoff)
+	}
+
+	if me.Jitter > 0 {
+		jitter := me.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		backoff -= backoff * jitter * rand.Float64()
+	}
+
+	return time.Duration(backoff)
+}
+
+// Parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/restpackio/gorestpack/gorestpacktest"
+)
+
+func Test_Retry_ServerError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"image":"https://cdn.restpack.io/a.png"}`))
+	}))
+	defer server.Close()
+
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+	resp, err := client.Capture("https://google.com/")
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if resp.Attempts != 3 || calls != 3 {
+		t.Errorf("Must succeed on third attempt, get: %d attempts, %d calls", resp.Attempts, calls)
+	}
+}
+
+func Test_Retry_NotRetryable(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"net::ERR_NAME_NOT_RESOLVED at https://google/"}`))
+	}))
+	defer server.Close()
+
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+	_, err := client.CaptureToReader("https://google/")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Attempts != 1 || calls != 1 {
+		t.Errorf("Must not retry navigation errors, get: %v after %d calls", err, calls)
+	}
+}
+
+func Test_Retry_Delay(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	if d := policy.delay(1, nil); d != 100*time.Millisecond {
+		t.Errorf("First delay must be initial backoff, get: %s", d)
+	}
+
+	if d := policy.delay(3, nil); d != 400*time.Millisecond {
+		t.Errorf("Third delay must be 400ms, get: %s", d)
+	}
+
+	if d := policy.delay(10, nil); d != time.Second {
+		t.Errorf("Delay must be capped, get: %s", d)
+	}
+
+	policy = RetryPolicy{MaxBackoff: 10 * time.Second}
+
+	if d := policy.delay(1, http.Header{"Retry-After": []string{"2"}}); d != 2*time.Second {
+		t.Errorf("Must honor Retry-After, get: %s", d)
+	}
+
+	if d := policy.delay(1, http.Header{"Retry-After": []string{"3600"}}); d != 10*time.Second {
+		t.Errorf("Retry-After must be capped, get: %s", d)
+	}
+}
+
+func Test_Retry_ConnectionError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer server.Close()
+
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+	_, err := client.CaptureToReader("https://google.com/")
+
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || requestErr.Attempts != 2 || atomic.LoadInt32(&calls) != 2 || requestErr.Unwrap() == nil {
+		t.Errorf("Must report attempts of connection errors, get: %v after %d calls", err, calls)
+	}
+}
+
+func Test_Retry_AttemptReporter(t *testing.T) {
+	server := newTestServer(t)
+	server.FailNext(gorestpacktest.FailServer)
+
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+
+	attempts := 0
+	ctx := ContextWithAttemptReporter(context.Background(), func(n int) { attempts = n })
+
+	if _, err := client.CaptureToImageContext(ctx, "https://google.com/"); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if attempts != 2 {
+		t.Errorf("Must report attempts of image captures, get: %d", attempts)
+	}
+}
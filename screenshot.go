@@ -2,33 +2,25 @@ package gorestpack
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"image"
 	"io"
 
 	_ "image/jpeg"
 	_ "image/png"
-
-	"github.com/eknkc/request"
 )
 
-// Create a new Screenshot Client with supplied restpack.io access key
-func NewScreenshotClient(accessToken string) ScreenshotClient {
-	return &screenshotClient{
-		client: &client{
-			httpClient:  request.New(),
-			accessToken: accessToken,
-			basePath:    "https://restpack.io/api/screenshot/v5",
-		},
-	}
+// Create a new Screenshot Client with supplied restpack.io access key and optional client options
+func NewScreenshotClient(accessToken string, options ...Option) ScreenshotClient {
+	return New(accessToken, options...).Screenshot()
 }
 
 // Options supplied to the Restpack Screenshot API for conversion
 type ScreenshotCaptureOptions struct {
 	// Capturing mode.
-	Mode string `json:"mode,omitempty"`
+	Mode Mode `json:"mode,omitempty"`
 	// Preferred image output format. If you need a raw html string you can pass html as format
-	Format string `json:"format,omitempty"`
+	Format Format `json:"format,omitempty"`
 	// Preferred viewport width in pixels.
 	Width int `json:"width,omitempty"`
 	// Preferred viewport height in pixels.
@@ -56,11 +48,11 @@ type ScreenshotCaptureOptions struct {
 	// Additional headers seperated with newline
 	Headers string `json:"headers,omitempty"`
 	// Force CSS media emulation for print or screen.
-	EmulateMedia string `json:"emulate_media,omitempty"`
+	EmulateMedia Media `json:"emulate_media,omitempty"`
 	// By default, any response from remote server outside http 200-299 status codes generates an error. If you wish to capture error pages, pass true.
 	AllowFailed bool `json:"allow_failed,omitempty"`
 	// Wait until window load event fires or network becomes idle before capturing the page.
-	Wait string `json:"wait,omitempty"`
+	Wait Wait `json:"wait,omitempty"`
 	// Wait until a DOM element matching the provided css selector becomes present on the page.
 	Shutter string `json:"shutter,omitempty"`
 	// Ensure that the captured document does not get cached / stored for further use
@@ -82,6 +74,11 @@ type screenshotCallOptions struct {
 	HTML string `json:"html,omitempty"`
 }
 
+// Private captures are never stored in the client's Cache
+func (me screenshotCallOptions) cacheable() bool {
+	return !me.Privacy
+}
+
 // Capture result from screenshot API
 type ScreenshotCaptureResult struct {
 	Image        string `json:"image,omitempty"`
@@ -90,6 +87,37 @@ type ScreenshotCaptureResult struct {
 	RemoteStatus string `json:"remote_status,omitempty"`
 	Cached       bool   `json:"cached,string,omitempty"`
 	URL          string `json:"url,omitempty"`
+	// Number of API requests made to obtain the result, including retries
+	Attempts int `json:"-"`
+}
+
+// Single capture of a batch. Either URL or HTML must be set
+type ScreenshotRequest struct {
+	URL     string
+	HTML    string
+	Options ScreenshotCaptureOptions
+}
+
+// Outcome of a single capture of a batch
+type ScreenshotBatchResult struct {
+	Request ScreenshotRequest
+	Result  ScreenshotCaptureResult
+	Err     error
+}
+
+// Screenshot running in the background
+type ScreenshotFuture struct {
+	*future
+	result ScreenshotCaptureResult
+}
+
+// Wait for the capture to complete and return its result. Giving up on ctx does not cancel the capture.
+func (me *ScreenshotFuture) Wait(ctx context.Context) (ScreenshotCaptureResult, error) {
+	if err := me.wait(ctx); err != nil {
+		return ScreenshotCaptureResult{}, err
+	}
+
+	return me.result, nil
 }
 
 // Restpack Screenshot API Client
@@ -108,6 +136,56 @@ type ScreenshotClient interface {
 	CaptureToReader(url string, options ...ScreenshotCaptureOptions) (io.Reader, error)
 	// Capture a HTML snippet and returna a reader for resulting image
 	CaptureHTMLToReader(html string, options ...ScreenshotCaptureOptions) (io.Reader, error)
+
+	// Same as Capture, aborting the request when ctx is cancelled
+	CaptureContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (ScreenshotCaptureResult, error)
+	// Same as CaptureHTML, aborting the request when ctx is cancelled
+	CaptureHTMLContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (ScreenshotCaptureResult, error)
+	// Same as CaptureToImage, aborting the request when ctx is cancelled
+	CaptureToImageContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (image.Image, error)
+	// Same as CaptureHTMLToImage, aborting the request when ctx is cancelled
+	CaptureHTMLToImageContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (image.Image, error)
+	// Same as CaptureToReader, aborting the request when ctx is cancelled
+	CaptureToReaderContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (io.Reader, error)
+	// Same as CaptureHTMLToReader, aborting the request when ctx is cancelled
+	CaptureHTMLToReaderContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (io.Reader, error)
+
+	// Capture a URL and return the resulting image streamed from the API response. The stream must be closed by the caller
+	CaptureToStream(url string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error)
+	// Capture a HTML snippet and return the resulting image streamed from the API response. The stream must be closed by the caller
+	CaptureHTMLToStream(html string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error)
+	// Same as CaptureToStream, aborting the request when ctx is cancelled
+	CaptureToStreamContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error)
+	// Same as CaptureHTMLToStream, aborting the request when ctx is cancelled
+	CaptureHTMLToStreamContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error)
+
+	// Capture a URL and stream the resulting image into w, returning the number of bytes written
+	CaptureToWriter(w io.Writer, url string, options ...ScreenshotCaptureOptions) (int64, error)
+	// Capture a HTML snippet and stream the resulting image into w, returning the number of bytes written
+	CaptureHTMLToWriter(w io.Writer, html string, options ...ScreenshotCaptureOptions) (int64, error)
+	// Capture a URL and atomically save the resulting image to path, returning the path written.
+	// If path is empty or a directory, the Filename option is used as the file name
+	CaptureToFile(path string, url string, options ...ScreenshotCaptureOptions) (string, error)
+	// Capture a HTML snippet and atomically save the resulting image to path, returning the path written.
+	// If path is empty or a directory, the Filename option is used as the file name
+	CaptureHTMLToFile(path string, html string, options ...ScreenshotCaptureOptions) (string, error)
+	// Same as CaptureToWriter, aborting the request when ctx is cancelled
+	CaptureToWriterContext(ctx context.Context, w io.Writer, url string, options ...ScreenshotCaptureOptions) (int64, error)
+	// Same as CaptureHTMLToWriter, aborting the request when ctx is cancelled
+	CaptureHTMLToWriterContext(ctx context.Context, w io.Writer, html string, options ...ScreenshotCaptureOptions) (int64, error)
+	// Same as CaptureToFile, aborting the request when ctx is cancelled
+	CaptureToFileContext(ctx context.Context, path string, url string, options ...ScreenshotCaptureOptions) (string, error)
+	// Same as CaptureHTMLToFile, aborting the request when ctx is cancelled
+	CaptureHTMLToFileContext(ctx context.Context, path string, html string, options ...ScreenshotCaptureOptions) (string, error)
+
+	// Capture many URLs or HTML snippets with bounded concurrency. Results are returned in request order,
+	// pairing each request with its result or error. If any capture fails, a *BatchError summarizing the failures is returned as well
+	CaptureBatch(ctx context.Context, requests []ScreenshotRequest, options BatchOptions) ([]ScreenshotBatchResult, error)
+
+	// Start capturing a URL in the background. Cancelling ctx or the future aborts the capture
+	CaptureAsync(ctx context.Context, url string, options ...ScreenshotCaptureOptions) *ScreenshotFuture
+	// Start capturing a HTML snippet in the background. Cancelling ctx or the future aborts the capture
+	CaptureHTMLAsync(ctx context.Context, html string, options ...ScreenshotCaptureOptions) *ScreenshotFuture
 }
 
 type screenshotClient struct {
@@ -115,150 +193,209 @@ type screenshotClient struct {
 }
 
 func (me *screenshotClient) Capture(url string, options ...ScreenshotCaptureOptions) (ScreenshotCaptureResult, error) {
-	opt := screenshotCallOptions{
-		URL:  url,
-		JSON: true,
-	}
+	return me.CaptureContext(context.Background(), url, options...)
+}
 
-	if len(options) > 0 {
-		opt.ScreenshotCaptureOptions = options[0]
-	}
+func (me *screenshotClient) CaptureHTML(html string, options ...ScreenshotCaptureOptions) (ScreenshotCaptureResult, error) {
+	return me.CaptureHTMLContext(context.Background(), html, options...)
+}
 
-	var res struct {
-		ScreenshotCaptureResult
-		Error string `json:"error"`
-	}
-	httpres, _, err := me.do("POST", "/capture").JSON(opt).EndStruct(&res)
+func (me *screenshotClient) CaptureToImage(url string, options ...ScreenshotCaptureOptions) (image.Image, error) {
+	return me.CaptureToImageContext(context.Background(), url, options...)
+}
 
-	if err != nil {
-		return ScreenshotCaptureResult{}, err
-	}
+func (me *screenshotClient) CaptureHTMLToImage(html string, options ...ScreenshotCaptureOptions) (image.Image, error) {
+	return me.CaptureHTMLToImageContext(context.Background(), html, options...)
+}
 
-	if httpres.StatusCode > 300 {
-		return res.ScreenshotCaptureResult, errors.New(res.Error)
-	}
+func (me *screenshotClient) CaptureToReader(url string, options ...ScreenshotCaptureOptions) (io.Reader, error) {
+	return me.CaptureToReaderContext(context.Background(), url, options...)
+}
 
-	return res.ScreenshotCaptureResult, err
+func (me *screenshotClient) CaptureHTMLToReader(html string, options ...ScreenshotCaptureOptions) (io.Reader, error) {
+	return me.CaptureHTMLToReaderContext(context.Background(), html, options...)
 }
 
-func (me *screenshotClient) CaptureHTML(html string, options ...ScreenshotCaptureOptions) (ScreenshotCaptureResult, error) {
-	opt := screenshotCallOptions{
-		HTML: html,
-		JSON: true,
-	}
+func (me *screenshotClient) CaptureContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (ScreenshotCaptureResult, error) {
+	return me.capture(ctx, newScreenshotCallOptions(url, "", true, options))
+}
 
-	if len(options) > 0 {
-		opt.ScreenshotCaptureOptions = options[0]
-	}
+func (me *screenshotClient) CaptureHTMLContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (ScreenshotCaptureResult, error) {
+	return me.capture(ctx, newScreenshotCallOptions("", html, true, options))
+}
 
-	var res struct {
-		ScreenshotCaptureResult
-		Error string `json:"error"`
-	}
-	httpres, _, err := me.do("POST", "/capture").JSON(opt).EndStruct(&res)
+func (me *screenshotClient) CaptureToImageContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (image.Image, error) {
+	return me.captureImage(ctx, newScreenshotCallOptions(url, "", false, options))
+}
 
-	if err != nil {
-		return ScreenshotCaptureResult{}, err
-	}
+func (me *screenshotClient) CaptureHTMLToImageContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (image.Image, error) {
+	return me.captureImage(ctx, newScreenshotCallOptions("", html, false, options))
+}
 
-	if httpres.StatusCode > 300 {
-		return res.ScreenshotCaptureResult, errors.New(res.Error)
-	}
+func (me *screenshotClient) CaptureToReaderContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (io.Reader, error) {
+	return me.captureReader(ctx, newScreenshotCallOptions(url, "", false, options))
+}
 
-	return res.ScreenshotCaptureResult, err
+func (me *screenshotClient) CaptureHTMLToReaderContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (io.Reader, error) {
+	return me.captureReader(ctx, newScreenshotCallOptions("", html, false, options))
 }
 
-func (me *screenshotClient) CaptureToImage(url string, options ...ScreenshotCaptureOptions) (image.Image, error) {
-	opt := screenshotCallOptions{
-		URL:  url,
-		JSON: false,
-	}
+func (me *screenshotClient) CaptureToStream(url string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error) {
+	return me.CaptureToStreamContext(context.Background(), url, options...)
+}
 
-	if len(options) > 0 {
-		opt.ScreenshotCaptureOptions = options[0]
-	}
+func (me *screenshotClient) CaptureHTMLToStream(html string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error) {
+	return me.CaptureHTMLToStreamContext(context.Background(), html, options...)
+}
 
-	resp, body, err := me.do("POST", "/capture").JSON(opt).End()
+func (me *screenshotClient) CaptureToStreamContext(ctx context.Context, url string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error) {
+	return me.stream(ctx, "POST", "/capture", newScreenshotCallOptions(url, "", false, options))
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (me *screenshotClient) CaptureHTMLToStreamContext(ctx context.Context, html string, options ...ScreenshotCaptureOptions) (io.ReadCloser, error) {
+	return me.stream(ctx, "POST", "/capture", newScreenshotCallOptions("", html, false, options))
+}
 
-	if resp.StatusCode > 300 {
-		return nil, errors.New(resp.Status)
-	}
+func (me *screenshotClient) CaptureToWriter(w io.Writer, url string, options ...ScreenshotCaptureOptions) (int64, error) {
+	return me.CaptureToWriterContext(context.Background(), w, url, options...)
+}
 
-	img, _, err := image.Decode(bytes.NewReader(body))
+func (me *screenshotClient) CaptureHTMLToWriter(w io.Writer, html string, options ...ScreenshotCaptureOptions) (int64, error) {
+	return me.CaptureHTMLToWriterContext(context.Background(), w, html, options...)
+}
 
-	return img, err
+func (me *screenshotClient) CaptureToFile(path string, url string, options ...ScreenshotCaptureOptions) (string, error) {
+	return me.CaptureToFileContext(context.Background(), path, url, options...)
 }
 
-func (me *screenshotClient) CaptureHTMLToImage(html string, options ...ScreenshotCaptureOptions) (image.Image, error) {
-	opt := screenshotCallOptions{
-		HTML: html,
-		JSON: false,
-	}
+func (me *screenshotClient) CaptureHTMLToFile(path string, html string, options ...ScreenshotCaptureOptions) (string, error) {
+	return me.CaptureHTMLToFileContext(context.Background(), path, html, options...)
+}
 
-	if len(options) > 0 {
-		opt.ScreenshotCaptureOptions = options[0]
-	}
+func (me *screenshotClient) CaptureToWriterContext(ctx context.Context, w io.Writer, url string, options ...ScreenshotCaptureOptions) (int64, error) {
+	stream, err := me.CaptureToStreamContext(ctx, url, options...)
+
+	return writeStream(w, stream, err)
+}
 
-	resp, body, err := me.do("POST", "/capture").JSON(opt).End()
+func (me *screenshotClient) CaptureHTMLToWriterContext(ctx context.Context, w io.Writer, html string, options ...ScreenshotCaptureOptions) (int64, error) {
+	stream, err := me.CaptureHTMLToStreamContext(ctx, html, options...)
 
-	if err != nil {
-		return nil, err
-	}
+	return writeStream(w, stream, err)
+}
+
+func (me *screenshotClient) CaptureToFileContext(ctx context.Context, path string, url string, options ...ScreenshotCaptureOptions) (string, error) {
+	opt := newScreenshotCallOptions(url, "", false, options)
+
+	return writeStreamToFile(path, opt.Filename, func() (io.ReadCloser, error) {
+		return me.stream(ctx, "POST", "/capture", opt)
+	})
+}
 
-	if resp.StatusCode > 300 {
-		return nil, errors.New(resp.Status)
+func (me *screenshotClient) CaptureHTMLToFileContext(ctx context.Context, path string, html string, options ...ScreenshotCaptureOptions) (string, error) {
+	opt := newScreenshotCallOptions("", html, false, options)
+
+	return writeStreamToFile(path, opt.Filename, func() (io.ReadCloser, error) {
+		return me.stream(ctx, "POST", "/capture", opt)
+	})
+}
+
+func (me *screenshotClient) CaptureBatch(ctx context.Context, requests []ScreenshotRequest, options BatchOptions) ([]ScreenshotBatchResult, error) {
+	results := make([]ScreenshotBatchResult, len(requests))
+
+	errs, err := runBatch(ctx, len(requests), options, func(ctx context.Context, i int) error {
+		req := requests[i]
+		res, err := me.capture(ctx, newScreenshotCallOptions(req.URL, req.HTML, true, []ScreenshotCaptureOptions{req.Options}))
+
+		results[i] = ScreenshotBatchResult{Request: req, Result: res, Err: err}
+
+		return err
+	})
+
+	// Requests skipped after ctx was cancelled only have their error set
+	for i := range results {
+		results[i].Request = requests[i]
+		results[i].Err = errs[i]
 	}
 
-	img, _, err := image.Decode(bytes.NewReader(body))
+	return results, err
+}
+
+func (me *screenshotClient) CaptureAsync(ctx context.Context, url string, options ...ScreenshotCaptureOptions) *ScreenshotFuture {
+	return me.captureAsync(ctx, newScreenshotCallOptions(url, "", true, options))
+}
 
-	return img, err
+func (me *screenshotClient) CaptureHTMLAsync(ctx context.Context, html string, options ...ScreenshotCaptureOptions) *ScreenshotFuture {
+	return me.captureAsync(ctx, newScreenshotCallOptions("", html, true, options))
 }
 
-func (me *screenshotClient) CaptureToReader(url string, options ...ScreenshotCaptureOptions) (io.Reader, error) {
+func newScreenshotCallOptions(url string, html string, jsonResult bool, options []ScreenshotCaptureOptions) screenshotCallOptions {
 	opt := screenshotCallOptions{
 		URL:  url,
-		JSON: false,
+		HTML: html,
+		JSON: jsonResult,
 	}
 
 	if len(options) > 0 {
 		opt.ScreenshotCaptureOptions = options[0]
 	}
 
-	resp, body, err := me.do("POST", "/capture").JSON(opt).End()
+	return opt
+}
+
+func (me *screenshotClient) capture(ctx context.Context, opt screenshotCallOptions) (ScreenshotCaptureResult, error) {
+	var res ScreenshotCaptureResult
+	resp, err := me.doStruct(ctx, "POST", "/capture", opt, &res)
 
 	if err != nil {
-		return nil, err
+		return ScreenshotCaptureResult{}, err
 	}
 
-	if resp.StatusCode > 300 {
-		return nil, errors.New(resp.Status)
-	}
+	res.Attempts = resp.attempts
 
-	return bytes.NewReader(body), err
+	return res, nil
 }
 
-func (me *screenshotClient) CaptureHTMLToReader(html string, options ...ScreenshotCaptureOptions) (io.Reader, error) {
-	opt := screenshotCallOptions{
-		HTML: html,
-		JSON: false,
-	}
+func (me *screenshotClient) captureAsync(ctx context.Context, opt screenshotCallOptions) *ScreenshotFuture {
+	f := &ScreenshotFuture{}
+	f.future = startFuture(ctx, func(ctx context.Context) error {
+		var err error
+		f.result, err = me.capture(ctx, opt)
+		return err
+	})
 
-	if len(options) > 0 {
-		opt.ScreenshotCaptureOptions = options[0]
+	return f
+}
+
+func (me *screenshotClient) captureBytes(ctx context.Context, opt screenshotCallOptions) ([]byte, error) {
+	resp, err := me.do(ctx, "POST", "/capture", opt)
+
+	if err != nil {
+		return nil, err
 	}
 
-	resp, body, err := me.do("POST", "/capture").JSON(opt).End()
+	return resp.data, nil
+}
+
+func (me *screenshotClient) captureImage(ctx context.Context, opt screenshotCallOptions) (image.Image, error) {
+	stream, err := me.stream(ctx, "POST", "/capture", opt)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode > 300 {
-		return nil, errors.New(resp.Status)
+	defer stream.Close()
+
+	img, _, err := image.Decode(stream)
+
+	return img, err
+}
+
+func (me *screenshotClient) captureReader(ctx context.Context, opt screenshotCallOptions) (io.Reader, error) {
+	body, err := me.captureBytes(ctx, opt)
+
+	if err != nil {
+		return nil, err
 	}
 
 	return bytes.NewReader(body), err
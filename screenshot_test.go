@@ -1,8 +1,13 @@
 package gorestpack
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 func Png(buf []byte) bool {
@@ -12,7 +17,7 @@ func Png(buf []byte) bool {
 }
 
 func Test_Screenshot_InvalidToken(t *testing.T) {
-	client := NewScreenshotClient("INVALID_TOKEN")
+	client := newTestScreenshotClient(t, "INVALID_TOKEN")
 	_, err := client.Capture("https://google.com/")
 
 	if err == nil {
@@ -22,12 +27,16 @@ func Test_Screenshot_InvalidToken(t *testing.T) {
 	if err.Error() != "The access token is invalid or you are not subscribed to any plan. Please visit the API console and choose your subscription plan." {
 		t.Errorf("Must return error with invalid token warning")
 	}
+
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Must match ErrInvalidToken")
+	}
 }
 
 func Test_Screenshot_Capture(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	resp, err := client.Capture("https://google.com/")
 
 	if err != nil {
@@ -42,7 +51,7 @@ func Test_Screenshot_Capture(t *testing.T) {
 func Test_Screenshot_Capture_404(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	_, err := client.Capture("https://google/")
 
 	if err == nil {
@@ -57,7 +66,7 @@ func Test_Screenshot_Capture_404(t *testing.T) {
 func Test_Screenshot_CaptureToReader(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	resp, err := client.CaptureToReader("https://google.com")
 
 	if err != nil {
@@ -79,14 +88,15 @@ func Test_Screenshot_CaptureToReader(t *testing.T) {
 func Test_Screenshot_CaptureToReader_404(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	_, err := client.CaptureToReader("https://google")
 
 	if err == nil {
 		t.Errorf("Must return error")
 	}
 
-	if err.Error() != "400 Bad Request" {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
 		t.Errorf("Must return 400 Bad Request")
 	}
 }
@@ -94,7 +104,7 @@ func Test_Screenshot_CaptureToReader_404(t *testing.T) {
 func Test_Screenshot_Image(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	_, err := client.CaptureToImage("https://google.com")
 
 	if err != nil {
@@ -105,14 +115,15 @@ func Test_Screenshot_Image(t *testing.T) {
 func Test_Screenshot_Image_404(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	_, err := client.CaptureToImage("https://google.coddm")
 
 	if err == nil {
 		t.Errorf("Must return error")
 	}
 
-	if err.Error() != "400 Bad Request" {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
 		t.Errorf("Must return 400 Bad Request")
 	}
 }
@@ -120,7 +131,7 @@ func Test_Screenshot_Image_404(t *testing.T) {
 func Test_Screenshot_Capture_HTML(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	resp, err := client.CaptureHTML("<h1>Test</h1>")
 
 	if err != nil {
@@ -135,7 +146,7 @@ func Test_Screenshot_Capture_HTML(t *testing.T) {
 func Test_Screenshot_Capture_HTML_Image(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	_, err := client.CaptureHTMLToImage("<h1>Test</h1>")
 
 	if err != nil {
@@ -146,7 +157,7 @@ func Test_Screenshot_Capture_HTML_Image(t *testing.T) {
 func Test_Screenshot_Capture_HTML_Reader(t *testing.T) {
 	token := os.Getenv("SS_TOKEN")
 
-	client := NewScreenshotClient(token)
+	client := newTestScreenshotClient(t, token)
 	resp, err := client.CaptureHTMLToReader("<h1>Test</h1>")
 
 	if err != nil {
@@ -164,3 +175,23 @@ func Test_Screenshot_Capture_HTML_Reader(t *testing.T) {
 		t.Errorf("Must return png file")
 	}
 }
+
+func Test_Screenshot_CaptureContext_Cancel(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CaptureContext(ctx, "https://google.com/")
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Must return context.DeadlineExceeded, get: %v", err)
+	}
+}
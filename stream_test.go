@@ -0,0 +1,61 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Stream_CaptureToStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("%PDF-1.4 test"))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimiterConfig{MaxInFlight: 1, FailFast: true})
+	client := NewHTMLToPDFClient("TOKEN", WithBaseURL(server.URL), WithLimiter(limiter))
+
+	stream, err := client.CaptureToStream("https://google.com/")
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if _, err := limiter.Acquire(context.Background()); err != ErrLimitExceeded {
+		t.Errorf("Stream must hold the in-flight slot until closed")
+	}
+
+	body, err := io.ReadAll(stream)
+
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if !Pdf(body) {
+		t.Errorf("Must return pdf file")
+	}
+
+	stream.Close()
+
+	if _, err := limiter.Acquire(context.Background()); err != nil {
+		t.Errorf("Closing the stream must release the in-flight slot, get: %v", err)
+	}
+}
+
+func Test_Stream_CaptureToStream_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"net::ERR_NAME_NOT_RESOLVED at https://google/"}`))
+	}))
+	defer server.Close()
+
+	client := NewScreenshotClient("TOKEN", WithBaseURL(server.URL))
+	stream, err := client.CaptureHTMLToStream("<h1>Test</h1>")
+
+	if stream != nil || !errors.Is(err, ErrRemoteNavigation) {
+		t.Errorf("Must return navigation error before streaming, get: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supplies the access token for each API request
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenProvider able to reload its token. When the API reports the token as invalid,
+// the client refreshes it and retries the request once.
+type TokenRefresher interface {
+	TokenProvider
+	Refresh(ctx context.Context) error
+}
+
+// Consult provider for the access token of each API request instead of the token supplied to the constructor
+func WithTokenProvider(provider TokenProvider) Option {
+	return func(cfg *config) {
+		cfg.tokenProvider = provider
+	}
+}
+
+// Function implementing TokenProvider
+type TokenProviderFunc func(ctx context.Context) (string, error)
+
+func (me TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return me(ctx)
+}
+
+// TokenProvider always returning token
+func StaticToken(token string) TokenProvider {
+	return staticToken(token)
+}
+
+type staticToken string
+
+func (me staticToken) Token(ctx context.Context) (string, error) {
+	return string(me), nil
+}
+
+// TokenProvider reading the token from the environment variable name on each request
+func EnvToken(name string) TokenProvider {
+	return TokenProviderFunc(func(ctx context.Context) (string, error) {
+		token := os.Getenv(name)
+
+		if token == "" {
+			return "", errors.New("gorestpack: environment variable " + name + " is not set")
+		}
+
+		return token, nil
+	})
+}
+
+// TokenProvider reading the token from a file, e.g. one maintained by a secrets manager.
+// The file is checked for modifications at most once per interval and reloaded when it changes.
+type FileToken struct {
+	path     string
+	interval time.Duration
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	checked time.Time
+}
+
+// Create a new FileToken for path, checking for changes at most once per interval
+func NewFileToken(path string, interval time.Duration) *FileToken {
+	return &FileToken{path: path, interval: interval}
+}
+
+func (me *FileToken) Token(ctx context.Context) (string, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	if me.token != "" && time.Since(me.checked) < me.interval {
+		return me.token, nil
+	}
+
+	if err := me.load(false); err != nil {
+		return "", err
+	}
+
+	return me.token, nil
+}
+
+// Reload the token from the file regardless of its modification time
+func (me *FileToken) Refresh(ctx context.Context) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.load(true)
+}
+
+func (me *FileToken) load(force bool) error {
+	info, err := os.Stat(me.path)
+
+	if err != nil {
+		return err
+	}
+
+	me.checked = time.Now()
+
+	if !force && me.token != "" && info.ModTime().Equal(me.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(me.path)
+
+	if err != nil {
+		return err
+	}
+
+	token := strings.TrimSpace(string(data))
+
+	if token == "" {
+		return errors.New("gorestpack: token file " + me.path + " is empty")
+	}
+
+	me.token = token
+	me.modTime = info.ModTime()
+
+	return nil
+}
+
+type tenantKey struct{}
+
+// Return a copy of ctx carrying tenant, for use with TenantToken
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant stored in ctx by ContextWithTenant
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// TokenProvider resolving the token of the tenant carried by the request context with lookup
+func TenantToken(lookup func(ctx context.Context, tenant string) (string, error)) TokenProvider {
+	return TokenProviderFunc(func(ctx context.Context) (string, error) {
+		tenant, ok := TenantFromContext(ctx)
+
+		if !ok {
+			return "", errors.New("gorestpack: no tenant in context")
+		}
+
+		return lookup(ctx, tenant)
+	})
+}
@@ -0,0 +1,68 @@
+package gorestpack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/restpackio/gorestpack/gorestpacktest"
+)
+
+func Test_Token_FileRefresh(t *testing.T) {
+	server := gorestpacktest.NewServer("NEW_TOKEN")
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("OLD_TOKEN\n"), 0600)
+
+	tokens := NewFileToken(path, time.Hour)
+
+	if token, err := tokens.Token(context.Background()); err != nil || token != "OLD_TOKEN" {
+		t.Fatalf("Must read token file, get: %s, %v", token, err)
+	}
+
+	os.WriteFile(path, []byte("NEW_TOKEN\n"), 0600)
+
+	client := NewScreenshotClient("", WithBaseURL(server.URL), WithTokenProvider(tokens))
+	resp, err := client.Capture("https://google.com/")
+
+	if err != nil {
+		t.Fatalf("Must refresh token and retry, get: %s", err.Error())
+	}
+
+	requests := server.Requests()
+
+	if resp.Attempts != 2 || len(requests) != 2 || requests[0].Token != "OLD_TOKEN" || requests[1].Token != "NEW_TOKEN" {
+		t.Errorf("Must retry once with refreshed token, get: %+v", requests)
+	}
+}
+
+func Test_Token_Tenant(t *testing.T) {
+	server := gorestpacktest.NewServer("TOKEN_A", "TOKEN_B")
+	defer server.Close()
+
+	tokens := map[string]string{"a": "TOKEN_A", "b": "TOKEN_B"}
+	provider := TenantToken(func(ctx context.Context, tenant string) (string, error) {
+		return tokens[tenant], nil
+	})
+
+	client := NewHTMLToPDFClient("", WithBaseURL(server.URL), WithTokenProvider(provider))
+
+	for _, tenant := range []string{"a", "b"} {
+		if _, err := client.CaptureContext(ContextWithTenant(context.Background(), tenant), "https://google.com/"); err != nil {
+			t.Errorf("Error: %s", err.Error())
+		}
+	}
+
+	if _, err := client.Capture("https://google.com/"); err == nil {
+		t.Errorf("Must fail without tenant")
+	}
+
+	requests := server.Requests()
+
+	if len(requests) != 2 || requests[0].Token != "TOKEN_A" || requests[1].Token != "TOKEN_B" {
+		t.Errorf("Must use tenant tokens, get: %+v", requests)
+	}
+}
@@ -0,0 +1,155 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Returned by a TokenPool when every token is ejected
+var ErrTokenPoolExhausted = errors.New("gorestpack: every token in the pool is ejected")
+
+// TokenProvider notified of the outcome of each API request.
+type TokenReporter interface {
+	TokenProvider
+	// Report the outcome of an API request made with token. Returning true retries the request with another token.
+	Report(token string, err error) bool
+}
+
+// Access token in a TokenPool
+type PoolToken struct {
+	Token string
+	// Relative share of requests sent with this token. Defaults to 1.
+	Weight int
+}
+
+// Usage statistics of a token in a TokenPool
+type TokenUsage struct {
+	// Last characters of the token, enough to tell tokens apart without revealing them.
+	Token        string
+	Requests     int64
+	Failures     int64
+	Ejections    int64
+	EjectedUntil time.Time
+}
+
+// TokenProvider distributing requests across several Restpack accounts with weighted round robin.
+// A token failing with a quota, rate limit or invalid token error is ejected for a while and the request is
+// retried with the next token.
+type TokenPool struct {
+	ejectFor time.Duration
+
+	mu     sync.Mutex
+	tokens []*poolEntry
+}
+
+type poolEntry struct {
+	PoolToken
+	current int
+	usage   TokenUsage
+}
+
+// Create a new TokenPool ejecting failing tokens for ejectFor, or one minute if zero
+func NewTokenPool(tokens []PoolToken, ejectFor time.Duration) *TokenPool {
+	if ejectFor <= 0 {
+		ejectFor = time.Minute
+	}
+
+	pool := &TokenPool{ejectFor: ejectFor}
+
+	for _, token := range tokens {
+		if token.Weight <= 0 {
+			token.Weight = 1
+		}
+
+		pool.tokens = append(pool.tokens, &poolEntry{PoolToken: token, usage: TokenUsage{Token: mask(token.Token)}})
+	}
+
+	return pool
+}
+
+func (me *TokenPool) Token(ctx context.Context) (string, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	now := time.Now()
+	total := 0
+	var best *poolEntry
+
+	// Smooth weighted round robin over the tokens that are not ejected
+	for _, entry := range me.tokens {
+		if now.Before(entry.usage.EjectedUntil) {
+			continue
+		}
+
+		entry.current += entry.Weight
+		total += entry.Weight
+
+		if best == nil || entry.current > best.current {
+			best = entry
+		}
+	}
+
+	if best == nil {
+		return "", ErrTokenPoolExhausted
+	}
+
+	best.current -= total
+	best.usage.Requests++
+
+	return best.Token, nil
+}
+
+func (me *TokenPool) Report(token string, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	exhausted := errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrRateLimited) ||
+		(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusPaymentRequired)
+
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	available := false
+
+	for _, entry := range me.tokens {
+		if entry.Token == token {
+			entry.usage.Failures++
+
+			if exhausted {
+				entry.usage.Ejections++
+				entry.usage.EjectedUntil = time.Now().Add(me.ejectFor)
+			}
+		} else if time.Now().After(entry.usage.EjectedUntil) {
+			available = true
+		}
+	}
+
+	return exhausted && available
+}
+
+// Usage statistics of every token in the pool
+func (me *TokenPool) Usage() []TokenUsage {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	usage := make([]TokenUsage, len(me.tokens))
+	for i, entry := range me.tokens {
+		usage[i] = entry.usage
+	}
+
+	return usage
+}
+
+// Keeps the last four characters of a token
+func mask(token string) string {
+	if len(token) <= 4 {
+		return "****"
+	}
+
+	return "****" + token[len(token)-4:]
+}
@@ -0,0 +1,50 @@
+package gorestpack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/restpackio/gorestpack/gorestpacktest"
+)
+
+func Test_TokenPool_Weighted(t *testing.T) {
+	pool := NewTokenPool([]PoolToken{{Token: "TOKEN_A", Weight: 2}, {Token: "TOKEN_B"}}, time.Minute)
+	counts := map[string]int{}
+
+	for i := 0; i < 6; i++ {
+		token, _ := pool.Token(context.Background())
+		counts[token]++
+	}
+
+	if counts["TOKEN_A"] != 4 || counts["TOKEN_B"] != 2 {
+		t.Errorf("Must distribute by weight, get: %v", counts)
+	}
+}
+
+func Test_TokenPool_Failover(t *testing.T) {
+	server := gorestpacktest.NewServer("TOKEN_B")
+	defer server.Close()
+
+	pool := NewTokenPool([]PoolToken{{Token: "TOKEN_A"}, {Token: "TOKEN_B"}}, time.Minute)
+	client := NewScreenshotClient("", WithBaseURL(server.URL), WithTokenProvider(pool))
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Capture("https://google.com/"); err != nil {
+			t.Fatalf("Must fail over to next token, get: %s", err.Error())
+		}
+	}
+
+	usage := pool.Usage()
+
+	if usage[0].Token != "****EN_A" || usage[0].Requests != 1 || usage[0].Ejections != 1 || usage[1].Requests != 3 {
+		t.Errorf("Must eject failing token, get: %+v", usage)
+	}
+
+	pool.Report("TOKEN_B", ErrRateLimited)
+
+	if _, err := client.Capture("https://google.com/"); !errors.Is(err, ErrTokenPoolExhausted) {
+		t.Errorf("Must fail when every token is ejected, get: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package gorestpack
+
+import (
+	"errors"
+	"strings"
+)
+
+// Matched by the errors returned by Validate
+var ErrInvalidOptions = errors.New("gorestpack: invalid capture options")
+
+// Problem with a single capture option
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (me *FieldError) Error() string {
+	return me.Field + " " + me.Message
+}
+
+// Every problem found by Validate, in field order
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (me *ValidationError) Error() string {
+	messages := make([]string, len(me.Errors))
+	for i, err := range me.Errors {
+		messages[i] = err.Error()
+	}
+
+	return ErrInvalidOptions.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (me *ValidationError) Is(target error) bool {
+	return target == ErrInvalidOptions
+}
+
+func (me *ValidationError) Unwrap() []error {
+	errs := make([]error, len(me.Errors))
+	for i, err := range me.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Send capture options without validating them first. Unknown Mode, Format and other enum values still fail to encode.
+func WithoutValidation() Option {
+	return func(cfg *config) {
+		cfg.skipValidation = true
+	}
+}
+
+// Check the options for problems the API would reject, without sending them
+func (me ScreenshotCaptureOptions) Validate() error {
+	v := &validator{}
+
+	v.oneOf("Mode", string(me.Mode), modes)
+	v.oneOf("Format", string(me.Format), formats)
+	v.nonNegative("Width", me.Width)
+	v.nonNegative("Height", me.Height)
+	v.nonNegative("ThumbnailWidth", me.ThumbnailWidth)
+	v.nonNegative("ThumbnailHeight", me.ThumbnailHeight)
+
+	if me.ThumbnailHeight != 0 && me.ThumbnailWidth == 0 {
+		v.add("ThumbnailHeight", "requires ThumbnailWidth")
+	}
+
+	v.nonNegative("Delay", me.Delay)
+	v.nonNegative("CacheTTL", me.CacheTTL)
+
+	mode, _ := canonicalValue(string(me.Mode), modes)
+
+	if me.ElementSelector != "" && Mode(mode) != ModeElement {
+		v.add("ElementSelector", `requires Mode "element"`)
+	}
+
+	if Mode(mode) == ModeElement && me.ElementSelector == "" {
+		v.add("ElementSelector", `is required with Mode "element"`)
+	}
+
+	v.oneOf("EmulateMedia", string(me.EmulateMedia), media)
+	v.oneOf("Wait", string(me.Wait), waits)
+
+	return v.err()
+}
+
+// Check the options for problems the API would reject, without sending them
+func (me HTMLToPDFCaptureOptions) Validate() error {
+	v := &validator{}
+
+	v.oneOf("PDFPage", string(me.PDFPage), pages)
+	v.oneOf("PDFOrientation", string(me.PDFOrientation), orientations)
+	v.nonNegative("Delay", me.Delay)
+	v.nonNegative("CacheTTL", me.CacheTTL)
+	v.oneOf("EmulateMedia", string(me.EmulateMedia), media)
+	v.oneOf("Wait", string(me.Wait), waits)
+
+	if me.PdfWidth != "" && me.PdfHeight == "" {
+		v.add("PdfWidth", "requires PdfHeight")
+	}
+
+	if me.PdfHeight != "" && me.PdfWidth == "" {
+		v.add("PdfHeight", "requires PdfWidth")
+	}
+
+	return v.err()
+}
+
+// Collects the FieldErrors of a Validate call
+type validator struct {
+	errs []*FieldError
+}
+
+func (me *validator) add(field string, message string) {
+	me.errs = append(me.errs, &FieldError{Field: field, Message: message})
+}
+
+func (me *validator) oneOf(field string, value string, allowed []string) {
+	if value == "" {
+		return
+	}
+
+	if _, ok := canonicalValue(value, allowed); !ok {
+		me.errs = append(me.errs, unknownValue(field, allowed))
+	}
+}
+
+func (me *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		me.add(field, "must not be negative")
+	}
+}
+
+func (me *validator) err() error {
+	if len(me.errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: me.errs}
+}
@@ -0,0 +1,81 @@
+package gorestpack
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Validate_Screenshot(t *testing.T) {
+	err := ScreenshotCaptureOptions{
+		Mode:            "page",
+		Format:          "gif",
+		ThumbnailHeight: 100,
+		ElementSelector: "#main",
+		Delay:           -1,
+		Wait:            "idle",
+		EmulateMedia:    "tv",
+	}.Validate()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("Must return ValidationError, get: %v", err)
+	}
+
+	fields := []string{"Mode", "Format", "ThumbnailHeight", "Delay", "ElementSelector", "EmulateMedia", "Wait"}
+
+	if len(validationErr.Errors) != len(fields) {
+		t.Fatalf("Must report every problem, get: %v", err)
+	}
+
+	for i, field := range fields {
+		if validationErr.Errors[i].Field != field {
+			t.Errorf("Must report %s, get: %s", field, validationErr.Errors[i].Field)
+		}
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Mode" {
+		t.Errorf("Must unwrap to field errors")
+	}
+
+	if err := (ScreenshotCaptureOptions{Mode: "element", ElementSelector: "#main", Format: "jpg", ThumbnailWidth: 100, ThumbnailHeight: 100}).Validate(); err != nil {
+		t.Errorf("Must accept valid options, get: %v", err)
+	}
+}
+
+func Test_Validate_HTMLToPDF(t *testing.T) {
+	err := HTMLToPDFCaptureOptions{PDFPage: "B7", PDFOrientation: "sideways", PdfWidth: "10cm"}.Validate()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 3 {
+		t.Errorf("Must report every problem, get: %v", err)
+	}
+
+	if err := (HTMLToPDFCaptureOptions{PDFPage: "A4", PDFOrientation: "landscape", PdfWidth: "10cm", PdfHeight: "20cm"}).Validate(); err != nil {
+		t.Errorf("Must accept valid options, get: %v", err)
+	}
+}
+
+func Test_Validate_Client(t *testing.T) {
+	server := newTestServer(t)
+	client := NewScreenshotClient(testToken, WithBaseURL(server.URL))
+
+	if _, err := client.Capture("https://google.com/", ScreenshotCaptureOptions{Delay: -1}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Must validate options before sending, get: %v", err)
+	}
+
+	if _, err := client.CaptureToStream("https://google.com/", ScreenshotCaptureOptions{Format: "gif"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Must validate streamed captures, get: %v", err)
+	}
+
+	if len(server.Requests()) != 0 {
+		t.Errorf("Must not send invalid options")
+	}
+
+	client = NewScreenshotClient(testToken, WithBaseURL(server.URL), WithoutValidation())
+	client.Capture("https://google.com/", ScreenshotCaptureOptions{Delay: -1})
+
+	if len(server.Requests()) != 1 {
+		t.Errorf("Must send options when validation is disabled")
+	}
+}